@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"mygo/internal/build"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func runBuild(args []string) int {
+	var common commonFlags
+	fs := newFlagSet("build")
+	common.register(fs)
+	common.registerIndex(fs)
+	common.registerFilters(fs)
+	common.registerStrict(fs)
+	force := fs.Bool("force", false, "re-render every page, ignoring fingerprints")
+	clean := fs.Bool("clean", false, "wipe public_dir before building")
+	manifest := fs.String("manifest", "", "path of the build manifest (default: next to the index)")
+	jobs := fs.Int("jobs", 0, "number of pages rendered in parallel (default: build.concurrency, or GOMAXPROCS)")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	cfg, err := common.loadConfig()
+	if err != nil {
+		return failConfig(err)
+	}
+	if *jobs < 0 {
+		fmt.Fprintln(os.Stderr, "build: -jobs must not be negative")
+		return exitUsage
+	}
+	if *jobs > 0 {
+		cfg.Build.Concurrency = *jobs
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	b := &build.Builder{
+		Cfg:       cfg,
+		IndexPath: common.indexPath,
+		Force:     *force,
+		Clean:     *clean,
+
+		ManifestPath: *manifest,
+	}
+	res, err := b.Run(ctx)
+	if res != nil {
+		printWarnings(res.Warnings)
+	}
+	if err != nil {
+		return fail("build", err)
+	}
+	fmt.Printf("built %d articles into %s: %d pages rendered, %d skipped, %d stale files removed\n",
+		res.Articles, cfg.Build.PublicDir, res.Rendered, res.Skipped, res.Pruned)
+	return exitOK
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"mygo/internal/ingest"
+	"mygo/internal/render"
+	"path/filepath"
+)
+
+func runCheck(args []string) int {
+	var common commonFlags
+	fs := newFlagSet("check")
+	common.register(fs)
+	common.registerIndex(fs)
+	common.registerStrict(fs)
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	// Load 内部已经做过 Validate
+	cfg, err := common.loadConfig()
+	if err != nil {
+		return failConfig(err)
+	}
+
+	tplDir := filepath.Join(cfg.Build.ThemeDir, cfg.Site.Theme, "templates")
+	if err := render.CheckThemeTemplates(tplDir); err != nil {
+		return fail("theme", err)
+	}
+	if _, err := render.NewTemplateRenderer(cfg.Build.ThemeDir, cfg.Site.Theme, cfg.Site.Location()); err != nil {
+		return fail("theme", err)
+	}
+
+	arts, warns, err := ingest.Ingest(common.ingestOptions(cfg))
+	if err != nil {
+		return fail("ingest", err)
+	}
+	printWarnings(warns)
+	if err := ingest.CheckStrict(cfg.Build.Strict, warns); err != nil {
+		return fail("check", err)
+	}
+	fmt.Printf("ok: %d articles, %d warnings\n", len(arts), len(warns))
+	return exitOK
+}
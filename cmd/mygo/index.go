@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"mygo/internal/index"
+	"mygo/internal/ingest"
+)
+
+func runIndex(args []string) int {
+	var common commonFlags
+	fs := newFlagSet("index")
+	common.register(fs)
+	common.registerIndex(fs)
+	common.registerFilters(fs)
+	common.registerStrict(fs)
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	cfg, err := common.loadConfig()
+	if err != nil {
+		return failConfig(err)
+	}
+
+	arts, warns, err := ingest.Ingest(common.ingestOptions(cfg))
+	if err != nil {
+		return fail("ingest", err)
+	}
+	printWarnings(warns)
+	if err := ingest.CheckStrict(cfg.Build.Strict, warns); err != nil {
+		return fail("index", err)
+	}
+
+	st, err := index.Open(index.OpenOptions{Path: common.indexPath})
+	if err != nil {
+		return fail("index", err)
+	}
+	defer st.Close()
+	if reason := st.ResetReason(); reason != nil {
+		printWarnings([]ingest.Warning{{Path: common.indexPath, Msg: reason.Error()}})
+	}
+
+	if err := st.Rebuild(arts, index.RebuildOptions{
+		IncludeDraft:  cfg.Build.IncludeDraft,
+		IncludeFuture: cfg.Build.Future,
+		Related:       cfg.Related.Count,
+	}); err != nil {
+		return fail("index", err)
+	}
+	fmt.Printf("indexed %d articles into %s\n", len(arts), common.indexPath)
+	return exitOK
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"mygo/internal/domain/config"
+	domainerr "mygo/internal/domain/errors"
+	"mygo/internal/ingest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// 内置时区数据库：CI 镜像里常常没有 /usr/share/zoneinfo，site.time_zone 也要能解析
+	_ "time/tzdata"
+)
+
+// version 在发布构建时通过 -ldflags "-X main.version=..." 注入
+var version = "dev"
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+type command struct {
+	name  string
+	short string
+	run   func(args []string) int
+}
+
+func commands() []command {
+	return []command{
+		{name: "build", short: "render the site into public_dir", run: runBuild},
+		{name: "serve", short: "start the dev server with live reload", run: runServe},
+		{name: "new", short: "create a new post in source_dir", run: runNew},
+		{name: "check", short: "validate config, theme and content", run: runCheck},
+		{name: "index", short: "rebuild the content index", run: runIndex},
+		{name: "search", short: "full-text search over the content index", run: runSearch},
+		{name: "version", short: "print version information", run: runVersion},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitUsage
+	}
+	name := args[0]
+	switch name {
+	case "-h", "-help", "--help", "help":
+		usage()
+		return exitOK
+	}
+	for _, c := range commands() {
+		if c.name == name {
+			return c.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "mygo: unknown command %q\n\n", name)
+	usage()
+	return exitUsage
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: mygo <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, c := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", c.name, c.short)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `run "mygo <command> -h" for command flags`)
+}
+
+// commonFlags 是子命令共用的参数。每个子命令只注册自己用得到的那几组，
+// 这样 "mygo serve -drafts=false" 这类不起作用的参数会直接报用法错误
+type commonFlags struct {
+	configPath string
+	indexPath  string
+	drafts     bool
+	future     bool
+	strict     bool
+}
+
+func (c *commonFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.configPath, "config", "site.yaml", "path to the site config file")
+}
+
+func (c *commonFlags) registerIndex(fs *flag.FlagSet) {
+	fs.StringVar(&c.indexPath, "index", ".mygo/index.db", "path to the index database")
+}
+
+// registerFilters 注册 -drafts / -future，决定哪些文章可见
+func (c *commonFlags) registerFilters(fs *flag.FlagSet) {
+	fs.BoolVar(&c.drafts, "drafts", false, "include draft posts")
+	fs.BoolVar(&c.future, "future", false, "include posts dated in the future")
+}
+
+func (c *commonFlags) registerStrict(fs *flag.FlagSet) {
+	fs.BoolVar(&c.strict, "strict", false, "exit non-zero when content produces warnings")
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("mygo "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// parseFlags 解析参数，返回非 -1 表示调用方应直接以该值退出
+func parseFlags(fs *flag.FlagSet, args []string) int {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	return -1
+}
+
+// fail 打印错误并映射退出码：校验类错误返回 2，其余返回 1
+func fail(prefix string, err error) int {
+	fmt.Fprintf(os.Stderr, "mygo: %s: %s\n", prefix, strings.TrimRight(err.Error(), "\n"))
+	if errors.Is(err, domainerr.ErrInvalid) {
+		return exitUsage
+	}
+	return exitError
+}
+
+// failConfig 用于配置无法加载的情况，一律视为用法错误
+func failConfig(err error) int {
+	fail("config", err)
+	return exitUsage
+}
+
+func (c *commonFlags) loadConfig() (config.Config, error) {
+	cfg, err := config.Load(c.configPath)
+	if err != nil {
+		return cfg, fmt.Errorf("load %s: %w", c.configPath, err)
+	}
+	if c.drafts {
+		cfg.Build.IncludeDraft = true
+	}
+	if c.future {
+		cfg.Build.Future = true
+	}
+	if c.strict {
+		cfg.Build.Strict = true
+	}
+	return cfg, nil
+}
+
+// ingestOptions 组装 ingest 选项；git 日期缓存放在索引旁边
+func (c *commonFlags) ingestOptions(cfg config.Config) ingest.Options {
+	opt := ingest.OptionsFromConfig(cfg)
+	opt.CacheDir = filepath.Dir(c.indexPath)
+	return opt
+}
+
+func printWarnings(warns []ingest.Warning) {
+	for _, w := range warns {
+		fmt.Fprintf(os.Stderr, "[warn] %s\n", w)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"mygo/internal/ingest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func runNew(args []string) int {
+	var common commonFlags
+	fs := newFlagSet("new")
+	common.register(fs)
+	slug := fs.String("slug", "", "slug of the new post (derived from the title by default)")
+	dir := fs.String("dir", "", "sub directory under source_dir")
+	force := fs.Bool("force", false, "overwrite an existing file")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, `usage: mygo new [flags] "Post Title"`)
+		fs.PrintDefaults()
+	}
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+	title := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if title == "" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	cfg, err := common.loadConfig()
+	if err != nil {
+		return failConfig(err)
+	}
+
+	s := ingest.ResolveSlug(ingest.FrontMatter{Slug: *slug, Title: title}, "")
+	if s == "" {
+		return fail("new", fmt.Errorf("cannot derive a slug from %q, use -slug", title))
+	}
+
+	path := filepath.Join(cfg.Build.SourceDir, *dir, s+".md")
+	if _, err := os.Stat(path); err == nil && !*force {
+		return fail("new", fmt.Errorf("%s already exists", path))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fail("new", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %q\n", title)
+	if *slug != "" {
+		fmt.Fprintf(&b, "slug: %s\n", s)
+	}
+	fmt.Fprintf(&b, "date: %s\n", time.Now().In(cfg.Site.Location()).Format("2006-01-02 15:04"))
+	b.WriteString("tags: []\n")
+	b.WriteString("category: \"\"\n")
+	b.WriteString("draft: true\n")
+	b.WriteString("---\n\n")
+
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fail("new", err)
+	}
+	fmt.Println(path)
+	return exitOK
+}
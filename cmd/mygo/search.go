@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"mygo/internal/domain/site"
+	"mygo/internal/index"
+	"os"
+	"strings"
+)
+
+func runSearch(args []string) int {
+	var common commonFlags
+	fs := newFlagSet("search")
+	common.register(fs)
+	common.registerIndex(fs)
+	common.registerFilters(fs)
+	limit := fs.Int("limit", 10, "maximum number of results")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, `usage: mygo search [flags] "query"`)
+		fmt.Fprintln(os.Stderr, "searches the index written by the last build or index run")
+		fs.PrintDefaults()
+	}
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+	query := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if query == "" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	cfg, err := common.loadConfig()
+	if err != nil {
+		return failConfig(err)
+	}
+
+	st, err := index.Open(index.OpenOptions{Path: common.indexPath})
+	if err != nil {
+		return fail("index", err)
+	}
+	defer st.Close()
+
+	hits, err := st.Search(query, index.SearchOptions{
+		Limit:         *limit,
+		IncludeDraft:  cfg.Build.IncludeDraft,
+		IncludeFuture: cfg.Build.Future,
+	})
+	if err != nil {
+		return fail("search", err)
+	}
+	if len(hits) == 0 {
+		fmt.Println("no results")
+		return exitOK
+	}
+	for i, h := range hits {
+		fmt.Printf("%d. %s  %s  (%.2f)\n", i+1, h.Meta.Title, site.PostURL(h.Meta), h.Score)
+		fmt.Printf("   %s\n", h.Snippet.Format("[", "]", nil))
+	}
+	return exitOK
+}
@@ -2,33 +2,40 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"mygo/internal/domain/config"
+	"errors"
 	"mygo/internal/serve"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 )
 
-func main() {
-	cfg, _ := config.Load("./site.yaml")
-	if err := cfg.Validate(); err != nil {
-		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(2)
+func runServe(args []string) int {
+	var common commonFlags
+	fs := newFlagSet("serve")
+	common.register(fs)
+	common.registerIndex(fs)
+	addr := fs.String("addr", ":8080", "listen address")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	cfg, err := common.loadConfig()
+	if err != nil {
+		return failConfig(err)
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	s, err := serve.New(cfg, ".mygo/index.db", cfg.Build.ThemeDir, cfg.Site.Theme)
+	s, err := serve.New(cfg, common.indexPath, cfg.Build.ThemeDir, cfg.Site.Theme)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "serve init error:", err.Error())
-		os.Exit(1)
+		return fail("serve init", err)
 	}
 	defer s.Close()
 
-	if err := s.ListenAndServe(ctx, ":8080"); err != nil {
-		fmt.Fprintln(os.Stderr, "serve error:", err.Error())
-		os.Exit(1)
+	if err := s.ListenAndServe(ctx, *addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fail("serve", err)
 	}
+	return exitOK
 }
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+func runVersion(args []string) int {
+	fs := newFlagSet("version")
+	if code := parseFlags(fs, args); code >= 0 {
+		return code
+	}
+
+	rev := ""
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && len(s.Value) >= 7 {
+				rev = s.Value[:7]
+			}
+		}
+	}
+	if rev != "" {
+		fmt.Printf("mygo %s (%s, %s %s/%s)\n", version, rev, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	} else {
+		fmt.Printf("mygo %s (%s %s/%s)\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	}
+	return exitOK
+}
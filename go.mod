@@ -3,6 +3,7 @@ module mygo
 go 1.25.1
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/fsnotify/fsnotify v1.9.0
 	github.com/yuin/goldmark v1.7.13
 	go.etcd.io/bbolt v1.4.3
@@ -2,72 +2,159 @@ package build
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"io/fs"
 	"mygo/internal/domain/config"
 	"mygo/internal/domain/content"
+	"mygo/internal/domain/site"
 	"mygo/internal/index"
 	"mygo/internal/ingest"
 	"mygo/internal/render"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 )
 
 type Builder struct {
 	Cfg       config.Config
 	IndexPath string
+	Force     bool // 忽略 fingerprint，强制重新渲染所有页面
+	Clean     bool // 构建前清空 public_dir
+
+	// ManifestPath 为空时写到 index 同目录下的 manifest.json
+	ManifestPath string
+
+	inc *incremental
+	out *outputs
+
+	mu    sync.Mutex
+	warns []ingest.Warning // 渲染阶段产生的警告，Run 结束时并入 Result.Warnings
 }
 
 type Result struct {
 	Articles int
+	Rendered int // 本次重新渲染的页面数
+	Skipped  int // fingerprint 未变化而跳过的页面数
+	Pruned   int // 删除的过期输出文件数
 	Warnings []ingest.Warning
 }
 
 func (b *Builder) Run(ctx context.Context) (*Result, error) {
-	arts, warns, err := ingest.Ingest(b.Cfg.Build.SourceDir)
+	opt := ingest.OptionsFromConfig(b.Cfg)
+	opt.CacheDir = filepath.Dir(b.IndexPath)
+	arts, warns, err := ingest.Ingest(opt)
 	if err != nil {
 		return nil, fmt.Errorf("ingest failed: %w", err)
 	}
+	// strict 模式下有警告就不写任何输出
+	if err := ingest.CheckStrict(b.Cfg.Build.Strict, warns); err != nil {
+		return &Result{Warnings: warns}, err
+	}
 
 	st, err := index.Open(index.OpenOptions{Path: b.IndexPath})
 	if err != nil {
 		return nil, fmt.Errorf("failed to open index: %w", err)
 	}
 	defer st.Close()
+	if reason := st.ResetReason(); reason != nil {
+		warns = append(warns, ingest.Warning{Path: b.IndexPath, Msg: reason.Error()})
+	}
 
-	if err := st.Rebuild(arts, index.RebuildOptions{
-		IncludeDraft: b.Cfg.Build.IncludeDraft,
-	}); err != nil {
+	// 平时只同步有变化的文章，-force 时整体重建
+	idxOpt := index.RebuildOptions{
+		IncludeDraft:  b.Cfg.Build.IncludeDraft,
+		IncludeFuture: b.Cfg.Build.Future,
+		Related:       b.Cfg.Related.Count,
+	}
+	if b.Force {
+		err = st.Rebuild(arts, idxOpt)
+	} else {
+		_, err = st.Sync(arts, idxOpt)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to rebuild index: %w", err)
 	}
 
 	md := render.NewMarkdownRenderer()
 	themeDir := b.Cfg.Build.ThemeDir
 	themeName := b.Cfg.Site.Theme
-	tpl, err := render.NewTemplateRenderer(themeDir, themeName)
+	tpl, err := render.NewTemplateRenderer(themeDir, themeName, b.Cfg.Site.Location())
 
 	if err != nil {
 		return nil, fmt.Errorf("load themes(%s): %w", themeDir, err)
 	}
 
 	outDir := b.Cfg.Build.PublicDir
+	if b.Clean {
+		if err := cleanOutDir(outDir, b.Cfg.Build.SourceDir, b.Cfg.Build.ThemeDir, b.IndexPath); err != nil {
+			return nil, fmt.Errorf("clean public: %w", err)
+		}
+	}
 	if err := os.MkdirAll(outDir, 0o755); err != nil {
 		return nil, fmt.Errorf("mkdir public: %w", err)
 	}
 
+	manifestPath := b.manifestPath()
+	prevManifest, err := loadManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	b.out = newOutputs(prevManifest)
+
+	b.inc, err = newIncremental(st, b.Cfg, b.Force)
+	if err != nil {
+		return nil, fmt.Errorf("load fingerprints: %w", err)
+	}
+
+	b.warns = nil
 	if err := b.buildAll(ctx, st, md, tpl, outDir, arts); err != nil {
 		return nil, err
 	}
+	warns = append(warns, b.warns...)
+	// 索引重置和渲染阶段（如 alias 冲突）也会产生警告，strict 模式下同样失败；
+	// 这时不清理旧文件、不保存 manifest 和 fingerprint，修好后的下一次构建会重新渲染
+	if err := ingest.CheckStrict(b.Cfg.Build.Strict, warns); err != nil {
+		return &Result{Warnings: warns}, err
+	}
+
+	pruned, err := b.out.prune(outDir)
+	if err != nil {
+		return nil, fmt.Errorf("prune stale outputs: %w", err)
+	}
+	if err := saveManifest(manifestPath, b.out.manifest(b.Cfg.Build.Now)); err != nil {
+		return nil, fmt.Errorf("save manifest: %w", err)
+	}
+	if err := st.SaveFingerprints(b.inc.next); err != nil {
+		return nil, fmt.Errorf("save fingerprints: %w", err)
+	}
 
 	return &Result{
 		Articles: len(arts),
+		Rendered: b.inc.rendered,
+		Skipped:  b.inc.skipped,
+		Pruned:   pruned,
 		Warnings: warns,
 	}, nil
 }
 
+func (b *Builder) warn(w ingest.Warning) {
+	b.mu.Lock()
+	b.warns = append(b.warns, w)
+	b.mu.Unlock()
+}
+
+func (b *Builder) manifestPath() string {
+	if b.ManifestPath != "" {
+		return b.ManifestPath
+	}
+	return filepath.Join(filepath.Dir(b.IndexPath), "manifest.json")
+}
+
 func (b *Builder) buildAll(
 	ctx context.Context,
 	st *index.Store,
@@ -76,46 +163,43 @@ func (b *Builder) buildAll(
 	outDir string,
 	arts []content.Article,
 ) error {
-	if err := b.buildHome(ctx, st, tpl, outDir); err != nil {
-		return fmt.Errorf("build home: %w", err)
-	}
-
-	if err := b.buildPosts(ctx, st, md, tpl, outDir, arts); err != nil {
-		return fmt.Errorf("build posts: %w", err)
-	}
-
-	if err := b.buildAllSeries(ctx, st, tpl, outDir); err != nil {
-		return fmt.Errorf("build series: %w", err)
-	}
-
-	if err := b.buildAllTags(ctx, st, tpl, outDir); err != nil {
-		return fmt.Errorf("build tags: %w", err)
-	}
-
-	if err := b.buildAllCategories(ctx, st, tpl, outDir); err != nil {
-		return fmt.Errorf("build categories: %w", err)
-	}
-
-	if err := b.buildNotFound(ctx, tpl, outDir); err != nil {
-		return fmt.Errorf("build 404: %w", err)
-	}
-
-	if err := b.buildArchives(ctx, st, tpl, outDir); err != nil {
-		return fmt.Errorf("build archives: %w", err)
-	}
-
-	if err := b.buildTagsOverview(ctx, st, tpl, outDir); err != nil {
-		return fmt.Errorf("build tags overview: %w", err)
-	}
+	return runStages(ctx, []stage{
+		{"build home", func() error { return b.buildHome(ctx, st, tpl, outDir) }},
+		{"build posts", func() error { return b.buildPosts(ctx, st, md, tpl, outDir, arts) }},
+		{"build series", func() error { return b.buildAllSeries(ctx, st, tpl, outDir) }},
+		{"build tags", func() error { return b.buildAllTags(ctx, st, tpl, outDir) }},
+		{"build categories", func() error { return b.buildAllCategories(ctx, st, tpl, outDir) }},
+		{"build 404", func() error { return b.buildNotFound(ctx, tpl, outDir) }},
+		{"build archives", func() error { return b.buildArchives(ctx, st, tpl, outDir) }},
+		{"build tags overview", func() error { return b.buildTagsOverview(ctx, st, tpl, outDir) }},
+		{"build categories overview", func() error { return b.buildCategoriesOverview(ctx, st, tpl, outDir) }},
+		{"build short links", func() error { return b.buildShortLinks(st, outDir) }},
+		{"build search index", func() error { return b.buildSearchIndex(st, outDir, arts) }},
+		{"build feeds", func() error { return b.buildFeeds(st, md, outDir, arts) }},
+		{"build sitemap", func() error { return b.buildSitemap(st, outDir) }},
+		// alias 放在其它页面之后，才能知道哪些路径已经被占用
+		{"build aliases", func() error { return b.buildAliases(st, outDir, arts) }},
+		{"copy static assets", func() error { return b.copyStaticAssets(outDir) }},
+	})
+}
 
-	if err := b.buildCategoriesOverview(ctx, st, tpl, outDir); err != nil {
-		return fmt.Errorf("build categories overview: %w", err)
-	}
+type stage struct {
+	name string
+	run  func() error
+}
 
-	if err := b.copyStaticAssets(outDir); err != nil {
-		return fmt.Errorf("copy static assets: %w", err)
+// runStages 依次执行各个构建阶段：单个阶段失败不影响其它阶段，最后统一报告；取消后立即停止
+func runStages(ctx context.Context, stages []stage) error {
+	var errs []error
+	for _, s := range stages {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if err := s.run(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 func (b *Builder) buildHome(
@@ -124,19 +208,38 @@ func (b *Builder) buildHome(
 	tpl render.Renderer,
 	outDir string,
 ) error {
-	opt := index.ListOptions{
-		Sort:         b.Cfg.Site.SortMode,
-		Page:         1,
-		Size:         20,
-		IncludeDraft: false,
-	}
-	items, err := st.HomeItems(opt)
+	// 一次取全量分组结果，再在内存里切页
+	items, _, err := st.HomeItems(index.ListOptions{
+		Sort:          b.Cfg.Site.SortMode,
+		All:           true,
+		IncludeDraft:  false,
+		IncludeFuture: b.Cfg.Build.Future,
+	})
 	if err != nil {
 		return err
 	}
 
+	size := b.Cfg.Paginate.Home
+	p := b.newPool(ctx)
+	for i, chunk := range paginate(items, size) {
+		p.Go(func() error {
+			return b.buildHomePage(ctx, st, tpl, outDir, chunk, i+1, size, len(items))
+		})
+	}
+	return p.Wait()
+}
+
+// buildHomePage 渲染首页的第 page 页
+func (b *Builder) buildHomePage(
+	ctx context.Context,
+	st *index.Store,
+	tpl render.Renderer,
+	outDir string,
+	chunk []index.HomeItem,
+	n, size, total int,
+) error {
 	var viewItems []render.HomeItem
-	for _, it := range items {
+	for _, it := range chunk {
 		switch it.Kind {
 		case index.HomePost:
 			viewItems = append(viewItems, render.HomeItem{
@@ -165,20 +268,25 @@ func (b *Builder) buildHome(
 		}
 	}
 
+	pager := render.NewPager("/", n, size, total)
 	page := render.HomePage{
-		Site:      b.Cfg.Site,
-		Items:     viewItems,
-		Page:      1,
-		PageSize:  opt.Size,
-		Generated: b.Cfg.Build.Now,
-		Title:     "Home",
-	}
-	htmlBytes, err := tpl.RenderHome(ctx, page)
+		Site:     b.Cfg.Site,
+		Items:    viewItems,
+		Page:     pager.Page,
+		PageSize: size,
+		Pager:    pager,
+		Title:    "Home",
+	}
+	hash, err := hashJSON(page)
 	if err != nil {
 		return err
 	}
+	page.Generated = b.Cfg.Build.Now
 
-	return writeFile(outDir, "index.html", htmlBytes)
+	rel := urlOutPath(site.PageURL("/", pager.Page))
+	return b.emit(outDir, rel, hash, func() ([]byte, error) {
+		return tpl.RenderHome(ctx, page)
+	})
 }
 
 func (b *Builder) buildPosts(
@@ -189,6 +297,7 @@ func (b *Builder) buildPosts(
 	outDir string,
 	arts []content.Article,
 ) error {
+	p := b.newPool(ctx)
 	for _, a := range arts {
 		meta := a.Meta
 
@@ -199,66 +308,112 @@ func (b *Builder) buildPosts(
 		if meta.Draft && !b.Cfg.Build.IncludeDraft {
 			continue
 		}
-
-		// 读取 markdown 原文
-		src, err := os.ReadFile(a.Body.SourcePath)
-		if err != nil {
-			return fmt.Errorf("read post source(%s): %w", a.Body.SourcePath, err)
+		// 已过期的不输出，定时发布的要加 -future 才输出
+		if meta.Expired || (meta.Scheduled && !b.Cfg.Build.Future) {
+			continue
 		}
 
-		// 去掉 frontmatter，只保留正文
-		fm, body, fmErr := ingest.ParseFrontMatter(src)
-		if fmErr == nil && fm.Title != "" {
-			_ = fm // 这里只是利用 ParseFrontMatter 切掉头部
-		} else {
-			body = src
+		p.Go(func() error {
+			return b.buildPost(ctx, st, md, tpl, outDir, a)
+		})
+	}
+	return p.Wait()
+}
+
+// buildPost 渲染单篇文章详情页
+func (b *Builder) buildPost(
+	ctx context.Context,
+	st *index.Store,
+	md *render.MarkdownRenderer,
+	tpl render.Renderer,
+	outDir string,
+	a content.Article,
+) error {
+	meta := a.Meta
+
+	// 系列信息：用于详情页 sidebar 展开
+	var seriesList []content.ArticleMeta
+	if meta.Series.Name != "" {
+		seriesList, _ = st.ListSeries(meta.Series.Name, index.ListOptions{
+			Sort:          b.Cfg.Site.SortMode,
+			All:           true,
+			IncludeDraft:  false,
+			IncludeFuture: b.Cfg.Build.Future,
+		})
+	}
+
+	pp := render.PostPage{
+		Site:        b.Cfg.Site,
+		Meta:        meta,
+		IsDraft:     meta.Draft,
+		IsScheduled: meta.Scheduled,
+		Title:       meta.Title,
+	}
+	pp.SeriesName = meta.Series.Name
+	pp.SeriesList = seriesList
+	related, err := st.Related(meta.Slug, index.ListOptions{IncludeFuture: b.Cfg.Build.Future})
+	if err != nil {
+		return fmt.Errorf("related posts(%s): %w", meta.Slug, err)
+	}
+	pp.Related = related
+	backlinks, err := st.Backlinks(meta.Slug, index.ListOptions{IncludeFuture: b.Cfg.Build.Future})
+	if err != nil {
+		return fmt.Errorf("backlinks(%s): %w", meta.Slug, err)
+	}
+	pp.Backlinks = backlinks
+
+	// 正文由源文件 hash 代表，其余输入都在 pp 里
+	hash, err := hashJSON(a.Body.ContentHash, pp)
+	if err != nil {
+		return err
+	}
+
+	// 路径：/post/YYYY/MM/DD/slug/index.html
+	y, mo, d := meta.Date.Date()
+	outPath := filepath.Join(
+		"post",
+		fmt.Sprintf("%04d", y),
+		fmt.Sprintf("%02d", mo),
+		fmt.Sprintf("%02d", d),
+		meta.Slug,
+		"index.html",
+	)
+	err = b.emit(outDir, outPath, hash, func() ([]byte, error) {
+		// 读取 markdown 正文，与 serve 一样用 ingest.ReadBody 切掉 front matter
+		body, err := ingest.ReadBody(a.Body.SourcePath)
+		if err != nil {
+			return nil, fmt.Errorf("read post source(%s): %w", a.Body.SourcePath, err)
 		}
 
 		// markdown -> HTML
 		mdResult, err := md.Render(body)
 		if err != nil {
-			return fmt.Errorf("markdown render(%s): %w", meta.Slug, err)
-		}
-
-		// 系列信息：用于详情页 sidebar 展开
-		var seriesList []content.ArticleMeta
-		if meta.Series.Name != "" {
-			seriesList, _ = st.ListSeries(meta.Series.Name, index.ListOptions{
-				Sort:         b.Cfg.Site.SortMode,
-				Page:         1,
-				Size:         1000,
-				IncludeDraft: false,
-			})
-		}
-
-		pp := render.PostPage{
-			Site:    b.Cfg.Site,
-			Meta:    meta,
-			HTML:    template.HTML(mdResult.HTML),
-			TOC:     mdResult.Headings,
-			IsDraft: meta.Draft,
-			Title:   meta.Title,
+			return nil, fmt.Errorf("markdown render(%s): %w", meta.Slug, err)
 		}
-		pp.SeriesName = meta.Series.Name
-		pp.SeriesList = seriesList
-		// TODO: Related 相关文章后面单独做，这里先留空切片
+		pp.HTML = template.HTML(mdResult.HTML)
+		pp.TOC = mdResult.Headings
 
 		htmlBytes, err := tpl.RenderPost(ctx, pp)
 		if err != nil {
-			return fmt.Errorf("render post(%s): %w", meta.Slug, err)
+			return nil, fmt.Errorf("render post(%s): %w", meta.Slug, err)
 		}
+		return htmlBytes, nil
+	})
+	if err != nil {
+		return err
+	}
+	return b.copyBundleAssets(outDir, filepath.Dir(outPath), a)
+}
 
-		// 路径：/post/YYYY/MM/DD/slug/index.html
-		y, mo, d := meta.Date.Date()
-		outPath := filepath.Join(
-			"post",
-			fmt.Sprintf("%04d", y),
-			fmt.Sprintf("%02d", mo),
-			fmt.Sprintf("%02d", d),
-			meta.Slug,
-			"index.html",
-		)
-		if err := writeFile(outDir, outPath, htmlBytes); err != nil {
+// copyBundleAssets 把页面包里的资源复制到文章输出目录，正文里的相对链接因此保持有效
+func (b *Builder) copyBundleAssets(outDir, postDir string, a content.Article) error {
+	for _, rel := range a.Body.Assets {
+		src := filepath.Join(a.Body.BundleDir, filepath.FromSlash(rel))
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return fmt.Errorf("read bundle asset(%s): %w", src, err)
+		}
+		if err := b.emitBytes(outDir, filepath.Join(postDir, filepath.FromSlash(rel)), data); err != nil {
 			return err
 		}
 	}
@@ -275,44 +430,58 @@ func (b *Builder) buildAllSeries(
 	if err != nil {
 		return err
 	}
-	for _, name := range names {
-		// 系列文章列表
-		items, err := st.ListSeries(name, index.ListOptions{
-			Sort:         b.Cfg.Site.SortMode,
-			Page:         1,
-			Size:         1000,
-			IncludeDraft: false,
-		})
-		if err != nil {
-			return err
-		}
-		if len(items) == 0 {
-			continue
-		}
-		sum, err := st.GetSeriesSummary(name, false)
-		if err != nil {
-			continue
-		}
-
-		sp := render.SeriesPage{
-			Site:   b.Cfg.Site,
-			Name:   name,
-			Items:  items,
-			Count:  sum.Count,
-			Latest: sum.LatestUpdated,
-		}
+	p := b.newPool(ctx)
+	for _, name := range uniqueByPath(names) {
+		p.Go(func() error {
+			// 系列文章列表
+			items, err := st.ListSeries(name, index.ListOptions{
+				Sort:          b.Cfg.Site.SortMode,
+				All:           true,
+				IncludeDraft:  false,
+				IncludeFuture: b.Cfg.Build.Future,
+			})
+			if err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				return nil
+			}
+			sum, err := st.GetSeriesSummary(name, false)
+			if err != nil {
+				return nil
+			}
 
-		htmlBytes, err := tpl.RenderSeries(ctx, sp)
-		if err != nil {
-			return fmt.Errorf("render series(%s): %w", name, err)
-		}
+			base := "/series/" + site.PathSegment(name) + "/"
+			size := b.Cfg.Paginate.Series
+			for i, chunk := range paginate(items, size) {
+				sp := render.SeriesPage{
+					Site:   b.Cfg.Site,
+					Name:   name,
+					Items:  chunk,
+					Count:  sum.Count,
+					Latest: sum.LatestUpdated,
+					Pager:  render.NewPager(base, i+1, size, len(items)),
+				}
 
-		outPath := filepath.Join("series", safePathSegment(name), "index.html")
-		if err := writeFile(outDir, outPath, htmlBytes); err != nil {
-			return err
-		}
+				hash, err := hashJSON(sp)
+				if err != nil {
+					return err
+				}
+				err = b.emit(outDir, urlOutPath(site.PageURL(base, i+1)), hash, func() ([]byte, error) {
+					htmlBytes, err := tpl.RenderSeries(ctx, sp)
+					if err != nil {
+						return nil, fmt.Errorf("render series(%s): %w", name, err)
+					}
+					return htmlBytes, nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 	}
-	return nil
+	return p.Wait()
 }
 
 // =============== tags /tags/<tag>/index.html ===============
@@ -325,10 +494,10 @@ func (b *Builder) buildAllTags(
 ) error {
 	// 简单做法：从全站 meta 里收集 tags
 	metas, err := st.List(index.ListOptions{
-		Sort:         b.Cfg.Site.SortMode,
-		Page:         1,
-		Size:         1000000,
-		IncludeDraft: false,
+		Sort:          b.Cfg.Site.SortMode,
+		All:           true,
+		IncludeDraft:  false,
+		IncludeFuture: b.Cfg.Build.Future,
 	})
 	if err != nil {
 		return err
@@ -344,42 +513,58 @@ func (b *Builder) buildAllTags(
 		}
 	}
 
-	for tag := range tagSet {
-		items, err := st.ListByTag(tag, index.ListOptions{
-			Sort:         b.Cfg.Site.SortMode,
-			Page:         1,
-			Size:         1000,
-			IncludeDraft: false,
-		})
-		if err != nil {
-			return err
-		}
-		if len(items) == 0 {
-			continue
-		}
-
-		lp := render.ListPage{
-			Site:      b.Cfg.Site,
-			Title:     fmt.Sprintf("Tag: %s", tag),
-			SubTitle:  "",
-			Items:     items,
-			Page:      1,
-			PageSize:  len(items),
-			Tag:       tag,
-			Generated: b.Cfg.Build.Now,
-		}
-
-		htmlBytes, err := tpl.RenderList(ctx, lp)
-		if err != nil {
-			return fmt.Errorf("render tag(%s): %w", tag, err)
-		}
+	p := b.newPool(ctx)
+	for _, tag := range uniqueByPath(setKeys(tagSet)) {
+		p.Go(func() error {
+			items, err := st.ListByTag(tag, index.ListOptions{
+				Sort:          b.Cfg.Site.SortMode,
+				All:           true,
+				IncludeDraft:  false,
+				IncludeFuture: b.Cfg.Build.Future,
+			})
+			if err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				return nil
+			}
 
-		outPath := filepath.Join("tags", safePathSegment(tag), "index.html")
-		if err := writeFile(outDir, outPath, htmlBytes); err != nil {
-			return err
-		}
+			base := "/tags/" + site.PathSegment(tag) + "/"
+			size := b.Cfg.Paginate.List
+			for i, chunk := range paginate(items, size) {
+				pager := render.NewPager(base, i+1, size, len(items))
+				lp := render.ListPage{
+					Site:     b.Cfg.Site,
+					Title:    fmt.Sprintf("Tag: %s", tag),
+					SubTitle: "",
+					Items:    chunk,
+					Page:     pager.Page,
+					PageSize: size,
+					Total:    len(items),
+					Pager:    pager,
+					Tag:      tag,
+				}
+				hash, err := hashJSON(lp)
+				if err != nil {
+					return err
+				}
+				lp.Generated = b.Cfg.Build.Now
+
+				err = b.emit(outDir, urlOutPath(site.PageURL(base, pager.Page)), hash, func() ([]byte, error) {
+					htmlBytes, err := tpl.RenderList(ctx, lp)
+					if err != nil {
+						return nil, fmt.Errorf("render tag(%s): %w", tag, err)
+					}
+					return htmlBytes, nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 	}
-	return nil
+	return p.Wait()
 }
 
 // =============== categories /categories/<cat>/index.html ===============
@@ -391,10 +576,10 @@ func (b *Builder) buildAllCategories(
 	outDir string,
 ) error {
 	metas, err := st.List(index.ListOptions{
-		Sort:         b.Cfg.Site.SortMode,
-		Page:         1,
-		Size:         1000000,
-		IncludeDraft: false,
+		Sort:          b.Cfg.Site.SortMode,
+		All:           true,
+		IncludeDraft:  false,
+		IncludeFuture: b.Cfg.Build.Future,
 	})
 	if err != nil {
 		return err
@@ -407,41 +592,57 @@ func (b *Builder) buildAllCategories(
 		}
 	}
 
-	for cat := range catSet {
-		items, err := st.ListByCategory(cat, index.ListOptions{
-			Sort:         b.Cfg.Site.SortMode,
-			Page:         1,
-			Size:         1000,
-			IncludeDraft: false,
-		})
-		if err != nil {
-			return err
-		}
-		if len(items) == 0 {
-			continue
-		}
-
-		lp := render.ListPage{
-			Site:      b.Cfg.Site,
-			Title:     fmt.Sprintf("Category: %s", cat),
-			Items:     items,
-			Page:      1,
-			PageSize:  len(items),
-			Category:  cat,
-			Generated: b.Cfg.Build.Now,
-		}
-
-		htmlBytes, err := tpl.RenderList(ctx, lp)
-		if err != nil {
-			return fmt.Errorf("render category(%s): %w", cat, err)
-		}
+	p := b.newPool(ctx)
+	for _, cat := range uniqueByPath(setKeys(catSet)) {
+		p.Go(func() error {
+			items, err := st.ListByCategory(cat, index.ListOptions{
+				Sort:          b.Cfg.Site.SortMode,
+				All:           true,
+				IncludeDraft:  false,
+				IncludeFuture: b.Cfg.Build.Future,
+			})
+			if err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				return nil
+			}
 
-		outPath := filepath.Join("categories", safePathSegment(cat), "index.html")
-		if err := writeFile(outDir, outPath, htmlBytes); err != nil {
-			return err
-		}
+			base := "/categories/" + site.PathSegment(cat) + "/"
+			size := b.Cfg.Paginate.List
+			for i, chunk := range paginate(items, size) {
+				pager := render.NewPager(base, i+1, size, len(items))
+				lp := render.ListPage{
+					Site:     b.Cfg.Site,
+					Title:    fmt.Sprintf("Category: %s", cat),
+					Items:    chunk,
+					Page:     pager.Page,
+					PageSize: size,
+					Total:    len(items),
+					Pager:    pager,
+					Category: cat,
+				}
+				hash, err := hashJSON(lp)
+				if err != nil {
+					return err
+				}
+				lp.Generated = b.Cfg.Build.Now
+
+				err = b.emit(outDir, urlOutPath(site.PageURL(base, pager.Page)), hash, func() ([]byte, error) {
+					htmlBytes, err := tpl.RenderList(ctx, lp)
+					if err != nil {
+						return nil, fmt.Errorf("render category(%s): %w", cat, err)
+					}
+					return htmlBytes, nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 	}
-	return nil
+	return p.Wait()
 }
 
 // =============== 404 /404.html ===============
@@ -452,14 +653,17 @@ func (b *Builder) buildNotFound(
 	outDir string,
 ) error {
 	page := render.NotFoundPage{
-		Site: b.Cfg.Site,
-		Path: "",
+		Site:  b.Cfg.Site,
+		Path:  "",
+		Title: "404",
 	}
-	htmlBytes, err := tpl.RenderNotFound(ctx, page)
+	hash, err := hashJSON(page)
 	if err != nil {
 		return err
 	}
-	return writeFile(outDir, "404.html", htmlBytes)
+	return b.emit(outDir, "404.html", hash, func() ([]byte, error) {
+		return tpl.RenderNotFound(ctx, page)
+	})
 }
 
 func writeFile(root, rel string, data []byte) error {
@@ -470,26 +674,67 @@ func writeFile(root, rel string, data []byte) error {
 	return os.WriteFile(full, data, 0o644)
 }
 
-func safePathSegment(s string) string {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return "untitled"
-	}
-	repl := func(r rune) rune {
-		switch {
-		case r >= 'a' && r <= 'z':
-			return r
-		case r >= 'A' && r <= 'Z':
-			return r
-		case r >= '0' && r <= '9':
-			return r
-		case r == '-' || r == '_':
-			return r
-		default:
-			return '-'
+// urlOutPath 把站内地址换成输出文件：目录形式写成 <dir>/index.html，带扩展名的直接写成该文件
+func urlOutPath(p string) string {
+	rel := strings.TrimPrefix(p, "/")
+	if rel == "" || strings.HasSuffix(p, "/") {
+		return path.Join(rel, "index.html")
+	}
+	return rel
+}
+
+// paginate 按 size 把列表切成若干页；空列表也返回一页，保证第一页总会输出
+func paginate[T any](items []T, size int) [][]T {
+	if size <= 0 {
+		size = len(items)
+	}
+	var pages [][]T
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		pages = append(pages, items[start:end])
+	}
+	if len(pages) == 0 {
+		pages = append(pages, nil)
+	}
+	return pages
+}
+
+func articlesBySlug(arts []content.Article) map[string]content.Article {
+	m := make(map[string]content.Article, len(arts))
+	for _, a := range arts {
+		m[a.Meta.Slug] = a
+	}
+	return m
+}
+
+// uniqueByPath 返回排好序的名字；映射到同一路径段的名字只保留第一个，
+// 避免并行渲染时多个任务写同一个文件
+func uniqueByPath(names []string) []string {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+
+	seen := make(map[string]bool, len(sorted))
+	out := sorted[:0]
+	for _, n := range sorted {
+		seg := site.PathSegment(n)
+		if seen[seg] {
+			continue
 		}
+		seen[seg] = true
+		out = append(out, n)
+	}
+	return out
+}
+
+func setKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
 	}
-	return strings.Map(repl, s)
+	return keys
 }
 
 func (b *Builder) buildArchives(
@@ -499,10 +744,10 @@ func (b *Builder) buildArchives(
 	outDir string,
 ) error {
 	metas, err := st.List(index.ListOptions{
-		Sort:         b.Cfg.Site.SortMode,
-		Page:         1,
-		Size:         1000000,
-		IncludeDraft: false,
+		Sort:          b.Cfg.Site.SortMode,
+		All:           true,
+		IncludeDraft:  false,
+		IncludeFuture: b.Cfg.Build.Future,
 	})
 	if err != nil {
 		return err
@@ -537,11 +782,13 @@ func (b *Builder) buildArchives(
 		Total:  total,
 	}
 
-	htmlBytes, err := tpl.RenderArchives(ctx, page)
+	hash, err := hashJSON(page)
 	if err != nil {
 		return err
 	}
-	return writeFile(outDir, filepath.Join("archives", "index.html"), htmlBytes)
+	return b.emit(outDir, filepath.Join("archives", "index.html"), hash, func() ([]byte, error) {
+		return tpl.RenderArchives(ctx, page)
+	})
 }
 
 func (b *Builder) buildTagsOverview(
@@ -551,10 +798,10 @@ func (b *Builder) buildTagsOverview(
 	outDir string,
 ) error {
 	metas, err := st.List(index.ListOptions{
-		Sort:         b.Cfg.Site.SortMode,
-		Page:         1,
-		Size:         1000000,
-		IncludeDraft: false,
+		Sort:          b.Cfg.Site.SortMode,
+		All:           true,
+		IncludeDraft:  false,
+		IncludeFuture: b.Cfg.Build.Future,
 	})
 	if err != nil {
 		return err
@@ -592,11 +839,13 @@ func (b *Builder) buildTagsOverview(
 		Total: len(stats),
 		Title: "All Tags",
 	}
-	htmlBytes, err := tpl.RenderTagsPage(ctx, page)
+	hash, err := hashJSON(page)
 	if err != nil {
 		return err
 	}
-	return writeFile(outDir, filepath.Join("tags", "index.html"), htmlBytes)
+	return b.emit(outDir, filepath.Join("tags", "index.html"), hash, func() ([]byte, error) {
+		return tpl.RenderTagsPage(ctx, page)
+	})
 }
 
 func (b *Builder) buildCategoriesOverview(
@@ -606,10 +855,10 @@ func (b *Builder) buildCategoriesOverview(
 	outDir string,
 ) error {
 	metas, err := st.List(index.ListOptions{
-		Sort:         b.Cfg.Site.SortMode,
-		Page:         1,
-		Size:         1000000,
-		IncludeDraft: false,
+		Sort:          b.Cfg.Site.SortMode,
+		All:           true,
+		IncludeDraft:  false,
+		IncludeFuture: b.Cfg.Build.Future,
 	})
 	if err != nil {
 		return err
@@ -644,11 +893,13 @@ func (b *Builder) buildCategoriesOverview(
 		Total:      len(stats),
 		Title:      "All Categories",
 	}
-	htmlBytes, err := tpl.RenderCategoriesPage(ctx, page)
+	hash, err := hashJSON(page)
 	if err != nil {
 		return err
 	}
-	return writeFile(outDir, filepath.Join("categories", "index.html"), htmlBytes)
+	return b.emit(outDir, filepath.Join("categories", "index.html"), hash, func() ([]byte, error) {
+		return tpl.RenderCategoriesPage(ctx, page)
+	})
 }
 
 func (b *Builder) copyStaticAssets(outDir string) error {
@@ -687,6 +938,10 @@ func (b *Builder) copyStaticAssets(outDir string) error {
 		if err != nil {
 			return err
 		}
-		return os.WriteFile(dst, in, 0o644)
+		if err := os.WriteFile(dst, in, 0o644); err != nil {
+			return err
+		}
+		b.out.record(rel, in)
+		return nil
 	})
 }
@@ -0,0 +1,39 @@
+package build
+
+import (
+	"mygo/internal/domain/content"
+	"mygo/internal/feed"
+	"mygo/internal/index"
+	"mygo/internal/render"
+)
+
+// =============== feeds /rss.xml /atom.xml ===============
+
+func (b *Builder) buildFeeds(
+	st *index.Store,
+	md *render.MarkdownRenderer,
+	outDir string,
+	arts []content.Article,
+) error {
+	items, err := feed.Collect(st, md, articlesBySlug(arts), b.Cfg, feed.Options{
+		IncludeDraft:  false,
+		IncludeFuture: b.Cfg.Build.Future,
+	})
+	if err != nil {
+		return err
+	}
+
+	rss, err := feed.RSS(b.Cfg.Site, items)
+	if err != nil {
+		return err
+	}
+	if err := b.emitBytes(outDir, feed.RSSFile, rss); err != nil {
+		return err
+	}
+
+	atom, err := feed.Atom(b.Cfg.Site, items)
+	if err != nil {
+		return err
+	}
+	return b.emitBytes(outDir, feed.AtomFile, atom)
+}
@@ -0,0 +1,163 @@
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	domainbuild "mygo/internal/domain/build"
+	"mygo/internal/domain/config"
+	"mygo/internal/index"
+	"mygo/internal/render"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// incremental 记录每个输出页面的 fingerprint，RenderHash 与上次相同且文件仍在时跳过渲染
+type incremental struct {
+	base  domainbuild.Fingerprint // ThemeHash / ConfigHash / RendererHash 对本次构建的所有页面相同
+	force bool
+
+	mu       sync.Mutex
+	prev     map[string]domainbuild.Fingerprint
+	next     map[string]domainbuild.Fingerprint
+	rendered int
+	skipped  int
+}
+
+func newIncremental(st *index.Store, cfg config.Config, force bool) (*incremental, error) {
+	themeHash, err := hashDir(filepath.Join(cfg.Build.ThemeDir, cfg.Site.Theme, "templates"))
+	if err != nil {
+		return nil, err
+	}
+	configHash, err := hashConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := st.LoadFingerprints()
+	if err != nil {
+		return nil, err
+	}
+	return &incremental{
+		base: domainbuild.Fingerprint{
+			ThemeHash:    themeHash,
+			ConfigHash:   configHash,
+			RendererHash: hashString(render.RendererVersion),
+		},
+		force: force,
+		prev:  prev,
+		next:  make(map[string]domainbuild.Fingerprint),
+	}, nil
+}
+
+// emit 以 contentHash 描述页面的输入数据；fingerprint 未变化时不调用 renderFn，也不重写文件
+func (b *Builder) emit(outDir, rel, contentHash string, renderFn func() ([]byte, error)) error {
+	inc := b.inc
+	fp := inc.base
+	fp.ContentHash = contentHash
+	fp.ComputeRenderHash()
+
+	inc.mu.Lock()
+	old, seen := inc.prev[rel]
+	inc.mu.Unlock()
+
+	if seen && !inc.force && old.RenderHash == fp.RenderHash && fileExists(filepath.Join(outDir, rel)) {
+		if err := b.out.keep(outDir, rel); err != nil {
+			return err
+		}
+		inc.mu.Lock()
+		inc.next[rel] = fp
+		inc.skipped++
+		inc.mu.Unlock()
+		return nil
+	}
+
+	data, err := renderFn()
+	if err != nil {
+		return err
+	}
+	if err := writeFile(outDir, rel, data); err != nil {
+		return err
+	}
+	b.out.record(rel, data)
+
+	inc.mu.Lock()
+	inc.next[rel] = fp
+	inc.rendered++
+	inc.mu.Unlock()
+	return nil
+}
+
+// emitBytes 用于内容已经算好的输出（feed、sitemap 等）：内容不变时不重写文件
+func (b *Builder) emitBytes(outDir, rel string, data []byte) error {
+	return b.emit(outDir, rel, hashBytes(data), func() ([]byte, error) {
+		return data, nil
+	})
+}
+
+// hashJSON 把页面数据序列化后取 hash，调用方需要先去掉每次构建都会变的字段（如 Generated）
+func hashJSON(parts ...interface{}) (string, error) {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, p := range parts {
+		if err := enc.Encode(p); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashString(s string) string {
+	return hashBytes([]byte(s))
+}
+
+func hashBytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashConfig 对配置取 hash；Build.Now 每次运行都不同，Concurrency / Strict 不影响输出，都不参与计算
+func hashConfig(cfg config.Config) (string, error) {
+	cfg.Build.Now = time.Time{}
+	cfg.Build.Concurrency = 0
+	cfg.Build.Strict = false
+	return hashJSON(cfg)
+}
+
+// hashDir 对目录下所有文件的相对路径与内容取 hash
+func hashDir(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		io.WriteString(h, filepath.ToSlash(rel))
+		h.Write([]byte{0})
+		_, err = io.Copy(h, f)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,127 @@
+package build
+
+import (
+	domainbuild "mygo/internal/domain/build"
+	"mygo/internal/domain/content"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// nextBuild 模拟下一次构建：上次记下的 fingerprint 变成 prev
+func nextBuild(prev *Builder, force bool) *Builder {
+	inc := &incremental{
+		base:  domainbuild.Fingerprint{ThemeHash: "t", ConfigHash: "c", RendererHash: "r"},
+		force: force,
+		prev:  map[string]domainbuild.Fingerprint{},
+		next:  map[string]domainbuild.Fingerprint{},
+	}
+	if prev != nil {
+		inc.prev = prev.inc.next
+	}
+	return &Builder{inc: inc, out: newOutputs(nil)}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestEmitSkipsUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	rel := filepath.Join("post", "a", "index.html")
+	full := filepath.Join(dir, rel)
+	emit := func(b *Builder, hash, data string) (called bool) {
+		t.Helper()
+		err := b.emit(dir, rel, hash, func() ([]byte, error) {
+			called = true
+			return []byte(data), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return called
+	}
+
+	first := nextBuild(nil, false)
+	if !emit(first, "h1", "v1") || readFile(t, full) != "v1" || first.inc.rendered != 1 {
+		t.Fatalf("first build did not render")
+	}
+
+	// hash 不变：不调用 renderFn，也不重写文件（磁盘上的内容保持原样），但仍记入 manifest
+	if err := os.WriteFile(full, []byte("on disk"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second := nextBuild(first, false)
+	if emit(second, "h1", "v2") {
+		t.Error("unchanged hash: renderFn called")
+	}
+	if got := readFile(t, full); got != "on disk" {
+		t.Errorf("unchanged hash: file rewritten to %q", got)
+	}
+	if second.inc.skipped != 1 || second.inc.rendered != 0 {
+		t.Errorf("skipped, rendered = %d, %d; want 1, 0", second.inc.skipped, second.inc.rendered)
+	}
+	if _, ok := second.out.next["post/a/index.html"]; !ok {
+		t.Error("skipped file missing from the manifest")
+	}
+	if _, ok := second.inc.next[rel]; !ok {
+		t.Error("skipped file missing from the fingerprints")
+	}
+
+	if !emit(nextBuild(second, false), "h2", "v3") || readFile(t, full) != "v3" {
+		t.Error("changed hash: not re-rendered")
+	}
+	if !emit(nextBuild(second, true), "h1", "v4") || readFile(t, full) != "v4" {
+		t.Error("force: not re-rendered")
+	}
+	if err := os.Remove(full); err != nil {
+		t.Fatal(err)
+	}
+	if !emit(nextBuild(second, false), "h1", "v5") || readFile(t, full) != "v5" {
+		t.Error("missing output: not re-rendered")
+	}
+}
+
+// 主题、配置或渲染器变化会改变 RenderHash，所有页面都要重新渲染
+func TestEmitRerendersOnBaseChange(t *testing.T) {
+	dir := t.TempDir()
+	first := nextBuild(nil, false)
+	if err := first.emitBytes(dir, "rss.xml", []byte("feed")); err != nil {
+		t.Fatal(err)
+	}
+	second := nextBuild(first, false)
+	second.inc.base.ThemeHash = "t2"
+	if err := second.emitBytes(dir, "rss.xml", []byte("feed")); err != nil {
+		t.Fatal(err)
+	}
+	if second.inc.rendered != 1 {
+		t.Errorf("theme change: rendered = %d, want 1", second.inc.rendered)
+	}
+}
+
+// 页面包的资源复制到文章输出目录下，保持相对路径
+func TestCopyBundleAssets(t *testing.T) {
+	src, out := t.TempDir(), t.TempDir()
+	writeTree(t, src, "diagram.png", "img/x.png")
+	a := content.Article{Body: content.BodyRef{BundleDir: src, Assets: []string{"diagram.png", "img/x.png"}}}
+	b := nextBuild(nil, false)
+	if err := b.copyBundleAssets(out, filepath.Join("post", "2024", "01", "02", "hello"), a); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"post/", "post/2024/", "post/2024/01/", "post/2024/01/02/", "post/2024/01/02/hello/",
+		"post/2024/01/02/hello/diagram.png", "post/2024/01/02/hello/img/", "post/2024/01/02/hello/img/x.png",
+	}
+	if got := listTree(t, out); !reflect.DeepEqual(got, want) {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if got := readFile(t, filepath.Join(out, "post", "2024", "01", "02", "hello", "img", "x.png")); got != "img/x.png" {
+		t.Errorf("copied asset = %q", got)
+	}
+}
@@ -0,0 +1,161 @@
+package build
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	domainbuild "mygo/internal/domain/build"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// outputs 收集本次构建写出（或因 fingerprint 未变而保留）的文件
+type outputs struct {
+	mu   sync.Mutex
+	prev map[string]domainbuild.ManifestEntry
+	next map[string]domainbuild.ManifestEntry
+}
+
+func newOutputs(prev *domainbuild.Manifest) *outputs {
+	o := &outputs{
+		prev: make(map[string]domainbuild.ManifestEntry),
+		next: make(map[string]domainbuild.ManifestEntry),
+	}
+	if prev != nil {
+		for _, e := range prev.Files {
+			o.prev[e.Path] = e
+		}
+	}
+	return o
+}
+
+// record 登记一个刚写出的文件
+func (o *outputs) record(rel string, data []byte) {
+	rel = filepath.ToSlash(rel)
+	o.mu.Lock()
+	o.next[rel] = domainbuild.ManifestEntry{
+		Path: rel,
+		Size: int64(len(data)),
+		Hash: hashBytes(data),
+	}
+	o.mu.Unlock()
+}
+
+// keep 登记一个没有重写的文件：优先沿用上次 manifest 的记录，没有时从磁盘读
+func (o *outputs) keep(outDir, rel string) error {
+	rel = filepath.ToSlash(rel)
+	o.mu.Lock()
+	e, ok := o.prev[rel]
+	o.mu.Unlock()
+	if !ok {
+		data, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		o.record(rel, data)
+		return nil
+	}
+	o.mu.Lock()
+	o.next[rel] = e
+	o.mu.Unlock()
+	return nil
+}
+
+func (o *outputs) manifest(now time.Time) domainbuild.Manifest {
+	m := domainbuild.Manifest{
+		Generated: now,
+		Files:     make([]domainbuild.ManifestEntry, 0, len(o.next)),
+	}
+	for _, e := range o.next {
+		m.Files = append(m.Files, e)
+	}
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+	return m
+}
+
+// prune 删除上次产出、这次不再产出的文件；只会动 manifest 里记录过的文件
+func (o *outputs) prune(outDir string) (int, error) {
+	n := 0
+	for rel := range o.prev {
+		if _, ok := o.next[rel]; ok {
+			continue
+		}
+		full := filepath.Join(outDir, filepath.FromSlash(rel))
+		if err := os.Remove(full); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return n, err
+		}
+		n++
+		removeEmptyParents(outDir, filepath.Dir(full))
+	}
+	return n, nil
+}
+
+// removeEmptyParents 自下而上删除空目录，直到 outDir 为止
+func removeEmptyParents(outDir, dir string) {
+	root := filepath.Clean(outDir)
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
+
+func loadManifest(path string) (*domainbuild.Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var m domainbuild.Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest(%s): %w", path, err)
+	}
+	return &m, nil
+}
+
+func saveManifest(path string, m domainbuild.Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// cleanOutDir 清空输出目录；拒绝删除明显不是输出目录的路径
+func cleanOutDir(outDir string, protect ...string) error {
+	abs, err := filepath.Abs(outDir)
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if abs == filepath.Dir(abs) || abs == cwd {
+		return fmt.Errorf("refusing to clean %s", outDir)
+	}
+	for _, p := range protect {
+		pa, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		if pa == abs || strings.HasPrefix(pa, abs+string(filepath.Separator)) {
+			return fmt.Errorf("refusing to clean %s: it contains %s", outDir, p)
+		}
+	}
+	return os.RemoveAll(abs)
+}
@@ -0,0 +1,99 @@
+package build
+
+import (
+	domainbuild "mygo/internal/domain/build"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func writeTree(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(f), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func listTree(t *testing.T, dir string) []string {
+	t.Helper()
+	var out []string
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if d.IsDir() {
+			rel += "/"
+		}
+		out = append(out, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// prune 只删除上次 manifest 里有、这次没有产出的文件，清掉因此变空的目录，不碰 manifest 以外的文件
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir,
+		"index.html", "kept.html", "old/a.html", "old/deep/b.html",
+		"shared/new.html", "shared/gone.html", "CNAME", "user/notes.txt")
+
+	prev := &domainbuild.Manifest{}
+	for _, p := range []string{"index.html", "kept.html", "old/a.html", "old/deep/b.html", "shared/gone.html", "missing.html"} {
+		prev.Files = append(prev.Files, domainbuild.ManifestEntry{Path: p})
+	}
+	o := newOutputs(prev)
+	o.record("index.html", []byte("new"))
+	o.record("shared/new.html", []byte("new"))
+	if err := o.keep(dir, "kept.html"); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := o.prune(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// missing.html 已经不在磁盘上，不计数
+	if n != 3 {
+		t.Errorf("pruned %d files, want 3", n)
+	}
+	want := []string{"CNAME", "index.html", "kept.html", "shared/", "shared/new.html", "user/", "user/notes.txt"}
+	if got := listTree(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("files after prune = %q, want %q", got, want)
+	}
+
+	var paths []string
+	for _, e := range o.manifest(time.Time{}).Files {
+		paths = append(paths, e.Path)
+	}
+	if want := []string{"index.html", "kept.html", "shared/new.html"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("manifest = %q, want %q", paths, want)
+	}
+}
+
+// 没有上次的 manifest（首次构建或 manifest 丢失）时不删除任何文件
+func TestPruneWithoutManifest(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, "index.html", "stale.html")
+	o := newOutputs(nil)
+	o.record("index.html", []byte("new"))
+	if n, err := o.prune(dir); err != nil || n != 0 {
+		t.Errorf("prune = %d, %v; want 0, nil", n, err)
+	}
+	if got, want := listTree(t, dir), []string{"index.html", "stale.html"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// pool 在有限个 goroutine 上执行渲染任务：ctx 取消后不再派发新任务，
+// 所有任务的错误都会被收集，而不是遇到第一个就返回
+type pool struct {
+	ctx  context.Context
+	sem  chan struct{}
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+func (b *Builder) newPool(ctx context.Context) *pool {
+	n := b.Cfg.Build.Concurrency
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	return &pool{ctx: ctx, sem: make(chan struct{}, n)}
+}
+
+// Go 等到有空闲 worker 后执行 fn；ctx 已取消时直接丢弃
+func (p *pool) Go(fn func() error) {
+	select {
+	case <-p.ctx.Done():
+		return
+	case p.sem <- struct{}{}:
+	}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+		if p.ctx.Err() != nil {
+			return
+		}
+		if err := fn(); err != nil {
+			p.mu.Lock()
+			p.errs = append(p.errs, err)
+			p.mu.Unlock()
+		}
+	}()
+}
+
+// Wait 等待所有任务结束，返回合并后的错误；错误按文本排序，保证输出稳定
+func (p *pool) Wait() error {
+	p.wg.Wait()
+	errs := p.errs
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+	if err := p.ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
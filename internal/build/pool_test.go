@@ -0,0 +1,98 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"mygo/internal/domain/config"
+	"sync/atomic"
+	"testing"
+)
+
+func testPool(ctx context.Context, n int) *pool {
+	b := &Builder{Cfg: config.Config{Build: config.BuildConfig{Concurrency: n}}}
+	return b.newPool(ctx)
+}
+
+func TestPoolJoinsAllErrors(t *testing.T) {
+	p := testPool(context.Background(), 2)
+	var ran atomic.Int32
+	for _, msg := range []string{"c", "", "a", "b"} {
+		p.Go(func() error {
+			ran.Add(1)
+			if msg == "" {
+				return nil
+			}
+			return errors.New(msg)
+		})
+	}
+	err := p.Wait()
+	if ran.Load() != 4 {
+		t.Errorf("ran %d tasks, want 4", ran.Load())
+	}
+	if err == nil || err.Error() != "a\nb\nc" {
+		t.Errorf("err = %v, want a, b and c in order", err)
+	}
+}
+
+func TestPoolStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := testPool(ctx, 1)
+	started := make(chan struct{})
+	p.Go(func() error {
+		close(started)
+		<-ctx.Done()
+		return errors.New("interrupted")
+	})
+	<-started
+	cancel()
+
+	var late atomic.Bool
+	for i := 0; i < 3; i++ {
+		p.Go(func() error {
+			late.Store(true)
+			return nil
+		})
+	}
+	err := p.Wait()
+	if late.Load() {
+		t.Error("task ran after cancel")
+	}
+	if !errors.Is(err, context.Canceled) || err.Error() != "interrupted\ncontext canceled" {
+		t.Errorf("err = %v, want the task error and context.Canceled", err)
+	}
+}
+
+func TestRunStages(t *testing.T) {
+	var ran []string
+	step := func(name string, err error) stage {
+		return stage{name, func() error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+	err := runStages(context.Background(), []stage{
+		step("one", errors.New("x")),
+		step("two", nil),
+		step("three", errors.New("y")),
+	})
+	if len(ran) != 3 {
+		t.Errorf("ran %q, want all three stages", ran)
+	}
+	if err == nil || err.Error() != "one: x\nthree: y" {
+		t.Errorf("err = %v, want errors of stage one and three", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran = nil
+	err = runStages(ctx, []stage{
+		step("one", errors.New("x")),
+		{"cancel", func() error { cancel(); return nil }},
+		step("three", nil),
+	})
+	if len(ran) != 1 {
+		t.Errorf("ran %q after cancel", ran)
+	}
+	if !errors.Is(err, context.Canceled) || err.Error() != "one: x\ncontext canceled" {
+		t.Errorf("err = %v, want stage one's error and context.Canceled", err)
+	}
+}
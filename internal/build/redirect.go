@@ -0,0 +1,94 @@
+package build
+
+import (
+	"fmt"
+	"mygo/internal/domain/content"
+	"mygo/internal/domain/site"
+	"mygo/internal/index"
+	"mygo/internal/ingest"
+	"mygo/internal/render"
+	"path/filepath"
+)
+
+// =============== aliases：旧地址 -> 跳转页 ===============
+
+func (b *Builder) buildAliases(st *index.Store, outDir string, arts []content.Article) error {
+	metas, err := st.List(index.ListOptions{
+		Sort:          b.Cfg.Site.SortMode,
+		All:           true,
+		IncludeDraft:  false,
+		IncludeFuture: b.Cfg.Build.Future,
+	})
+	if err != nil {
+		return err
+	}
+	sources := make(map[string]string, len(arts))
+	for _, a := range arts {
+		sources[a.Meta.Slug] = a.Body.SourcePath
+	}
+
+	// 已经生成的页面（文章、列表及其分页、归档、feed、搜索索引、短链等）优先，alias 不能覆盖它们
+	b.inc.mu.Lock()
+	taken := make(map[string]string, len(b.inc.next))
+	for rel := range b.inc.next {
+		taken[filepath.ToSlash(rel)] = "a generated page"
+	}
+	b.inc.mu.Unlock()
+
+	for _, m := range metas {
+		target := site.PostURL(m)
+		for _, alias := range m.Aliases {
+			p := site.AliasPath(m, alias)
+			if p == "" {
+				continue
+			}
+			rel := urlOutPath(p)
+			if owner, ok := taken[rel]; ok {
+				b.warn(ingest.Warning{
+					Path: sources[m.Slug],
+					Msg:  fmt.Sprintf("alias %q collides with %s at %s, skipped", alias, owner, p),
+				})
+				continue
+			}
+			taken[rel] = fmt.Sprintf("an alias of %q", m.Slug)
+
+			data, err := render.RedirectHTML(target, site.AbsURL(b.Cfg.Site.SiteURL, target))
+			if err != nil {
+				return err
+			}
+			if err := b.emitBytes(outDir, rel, data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// =============== short links /s/<id>/ ===============
+
+func (b *Builder) buildShortLinks(st *index.Store, outDir string) error {
+	metas, err := st.List(index.ListOptions{
+		Sort:          b.Cfg.Site.SortMode,
+		All:           true,
+		IncludeDraft:  false,
+		IncludeFuture: b.Cfg.Build.Future,
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range metas {
+		short := site.ShortURL(m)
+		if short == "" {
+			continue
+		}
+		target := site.PostURL(m)
+		data, err := render.RedirectHTML(target, site.AbsURL(b.Cfg.Site.SiteURL, target))
+		if err != nil {
+			return err
+		}
+		if err := b.emitBytes(outDir, urlOutPath(short), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
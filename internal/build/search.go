@@ -0,0 +1,29 @@
+package build
+
+import (
+	"mygo/internal/domain/content"
+	"mygo/internal/index"
+	"mygo/internal/search"
+)
+
+// =============== search /search_index.json ===============
+
+func (b *Builder) buildSearchIndex(
+	st *index.Store,
+	outDir string,
+	arts []content.Article,
+) error {
+	entries, err := search.Build(st, articlesBySlug(arts), search.Options{
+		Sort:          b.Cfg.Site.SortMode,
+		IncludeDraft:  false,
+		IncludeFuture: b.Cfg.Build.Future,
+	})
+	if err != nil {
+		return err
+	}
+	data, err := search.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return b.emitBytes(outDir, search.FileName, data)
+}
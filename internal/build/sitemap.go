@@ -0,0 +1,116 @@
+package build
+
+import (
+	"mygo/internal/domain/site"
+	"mygo/internal/index"
+	"mygo/internal/sitemap"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =============== /sitemap.xml /robots.txt ===============
+
+func (b *Builder) buildSitemap(st *index.Store, outDir string) error {
+	urls, err := b.collectSitemapURLs(st)
+	if err != nil {
+		return err
+	}
+	files, err := sitemap.Build(b.Cfg.Site.SiteURL, urls)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := b.emitBytes(outDir, f.Name, f.Data); err != nil {
+			return err
+		}
+	}
+	return b.emitBytes(outDir, sitemap.RobotsFile, sitemap.Robots(b.Cfg.Site.SiteURL, b.Cfg.Robots))
+}
+
+// collectSitemapURLs 收集所有会输出的页面，路径与 build 写出的文件保持一致
+func (b *Builder) collectSitemapURLs(st *index.Store) ([]sitemap.URL, error) {
+	metas, err := st.List(index.ListOptions{
+		Sort:          b.Cfg.Site.SortMode,
+		All:           true,
+		IncludeDraft:  false,
+		IncludeFuture: b.Cfg.Build.Future,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var latest time.Time
+	tagMod := make(map[string]time.Time)
+	catMod := make(map[string]time.Time)
+	var posts []sitemap.URL
+	for _, m := range metas {
+		if m.Updated.After(latest) {
+			latest = m.Updated
+		}
+		for _, t := range m.Tags {
+			if t != "" && m.Updated.After(tagMod[t]) {
+				tagMod[t] = m.Updated
+			}
+		}
+		if c := strings.TrimSpace(m.Category); c != "" && m.Updated.After(catMod[c]) {
+			catMod[c] = m.Updated
+		}
+		if m.NoIndex {
+			continue
+		}
+		posts = append(posts, sitemap.URL{Path: site.PostURL(m), LastMod: m.Updated})
+	}
+
+	urls := []sitemap.URL{
+		{Path: "/", LastMod: latest},
+		{Path: "/archives/", LastMod: latest},
+		{Path: "/tags/", LastMod: latest},
+		{Path: "/categories/", LastMod: latest},
+	}
+	urls = append(urls, posts...)
+
+	names, err := st.ListAllSeriesNames()
+	if err != nil {
+		return nil, err
+	}
+	seriesMod := make(map[string]time.Time, len(names))
+	for _, name := range names {
+		sum, err := st.GetSeriesSummary(name, false)
+		if err != nil {
+			continue
+		}
+		seriesMod[name] = sum.LatestUpdated
+	}
+
+	urls = append(urls, termURLs("/series/", seriesMod)...)
+	urls = append(urls, termURLs("/tags/", tagMod)...)
+	urls = append(urls, termURLs("/categories/", catMod)...)
+	return urls, nil
+}
+
+// termURLs 生成系列 / 标签 / 分类页的地址。与 uniqueByPath 一样按 PathSegment 去重：
+// 写法不同但路径相同的名字（如 "a b" 与 "a-b"）只对应一个页面，lastmod 取其中最新的
+func termURLs(prefix string, mods map[string]time.Time) []sitemap.URL {
+	bySeg := make(map[string]time.Time, len(mods))
+	for name, mod := range mods {
+		seg := site.PathSegment(name)
+		if cur, ok := bySeg[seg]; !ok || mod.After(cur) {
+			bySeg[seg] = mod
+		}
+	}
+	segs := make([]string, 0, len(bySeg))
+	for seg := range bySeg {
+		segs = append(segs, seg)
+	}
+	sort.Strings(segs)
+
+	urls := make([]sitemap.URL, 0, len(segs))
+	for _, seg := range segs {
+		urls = append(urls, sitemap.URL{
+			Path:    prefix + seg + "/",
+			LastMod: bySeg[seg],
+		})
+	}
+	return urls
+}
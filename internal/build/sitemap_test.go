@@ -0,0 +1,80 @@
+package build
+
+import (
+	"mygo/internal/domain/content"
+	"mygo/internal/domain/site"
+	"mygo/internal/index"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *index.Store {
+	t.Helper()
+	st, err := index.Open(index.OpenOptions{Path: filepath.Join(t.TempDir(), "index.db")})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+func day(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+
+// 写法不同但 PathSegment 相同的标签 / 分类 / 系列在 sitemap 里只出现一次，lastmod 取最新的
+func TestSitemapURLsDedupeByPath(t *testing.T) {
+	post := func(slug string, d int, edit func(m *content.ArticleMeta)) content.Article {
+		a := content.Article{Meta: content.ArticleMeta{Title: slug, Slug: slug, Date: day(d), Updated: day(d)}}
+		edit(&a.Meta)
+		return a
+	}
+	arts := []content.Article{
+		post("a", 1, func(m *content.ArticleMeta) {
+			m.Tags = []string{"a b", "x"}
+			m.Category = "C d"
+			m.Series = content.Series{Name: "S 1"}
+		}),
+		post("b", 3, func(m *content.ArticleMeta) {
+			m.Tags = []string{"a-b"}
+			m.Category = "C-d"
+		}),
+		post("c", 2, func(m *content.ArticleMeta) {
+			m.Series = content.Series{Name: "S-1"}
+			m.NoIndex = true
+		}),
+	}
+	st := openTestStore(t)
+	if err := st.Rebuild(arts, index.RebuildOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &Builder{}
+	urls, err := b.collectSitemapURLs(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]time.Time, len(urls))
+	for _, u := range urls {
+		if _, dup := got[u.Path]; dup {
+			t.Errorf("duplicate sitemap URL %s", u.Path)
+		}
+		got[u.Path] = u.LastMod
+	}
+	want := map[string]time.Time{
+		"/": day(3), "/archives/": day(3), "/tags/": day(3), "/categories/": day(3),
+		site.PostURL(arts[0].Meta): day(1),
+		site.PostURL(arts[1].Meta): day(3),
+		"/series/S-1/":             day(2),
+		"/tags/a-b/":               day(3),
+		"/tags/x/":                 day(1),
+		"/categories/C-d/":         day(3),
+	}
+	if len(got) != len(want) {
+		t.Errorf("sitemap has %d URLs, want %d: %v", len(got), len(want), got)
+	}
+	for p, mod := range want {
+		if g, ok := got[p]; !ok || !g.Equal(mod) {
+			t.Errorf("%s lastmod = %v (present %v), want %v", p, g, ok, mod)
+		}
+	}
+}
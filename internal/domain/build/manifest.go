@@ -0,0 +1,16 @@
+package build
+
+import "time"
+
+// Manifest 记录一次构建输出到 public_dir 的全部文件，
+// 下一次构建据此删除不再产出的旧文件，部署工具也可以直接用它做增量上传
+type Manifest struct {
+	Generated time.Time       `json:"generated"`
+	Files     []ManifestEntry `json:"files"` // 按 Path 排序
+}
+
+type ManifestEntry struct {
+	Path string `json:"path"` // 相对 public_dir，统一使用 "/" 分隔
+	Size int64  `json:"size"`
+	Hash string `json:"sha256"`
+}
@@ -5,14 +5,21 @@ import (
 	domainerr "mygo/internal/domain/errors"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type Config struct {
-	Site   SiteConfig   `yaml:"site"`
-	Build  BuildConfig  `yaml:"build"`
-	Assets AssetsConfig `yaml:"assets"`
+	Site     SiteConfig     `yaml:"site"`
+	Build    BuildConfig    `yaml:"build"`
+	Feed     FeedConfig     `yaml:"feed"`
+	Robots   RobotsConfig   `yaml:"robots"`
+	Paginate PaginateConfig `yaml:"paginate"`
+	Reading  ReadingConfig  `yaml:"reading"`
+	Summary  SummaryConfig  `yaml:"summary"`
+	Related  RelatedConfig  `yaml:"related"`
+	Assets   AssetsConfig   `yaml:"assets"`
 }
 
 type SiteConfig struct {
@@ -27,6 +34,19 @@ type SiteConfig struct {
 	Description string   `yaml:"description"`
 }
 
+// Location 返回 time_zone 对应的时区；未配置或无法识别时用本机时区（Validate 会拒绝无法识别的值）
+func (s SiteConfig) Location() *time.Location {
+	tz := strings.TrimSpace(s.TimeZone)
+	if tz == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
 type SortMode string
 
 const (
@@ -40,9 +60,57 @@ type BuildConfig struct {
 	ThemeDir     string    `yaml:"theme_dir"`
 	BasePath     string    `yaml:"base_path"`
 	IncludeDraft bool      `yaml:"include_draft"`
+	Future       bool      `yaml:"future"`      // 输出日期在未来的定时文章
+	GitDates     bool      `yaml:"git_dates"`   // front matter 没写日期时取 git 首次 / 最后提交时间
+	Concurrency  int       `yaml:"concurrency"` // 并行渲染的 worker 数，0 表示 GOMAXPROCS
+	Strict       bool      `yaml:"strict"`      // 有任何内容警告时以非零状态退出
 	Now          time.Time `yaml:"-"`
 }
 
+// MaxPageSize 是列表查询每页条数的上限
+const MaxPageSize = 100
+
+// PaginateConfig 各类列表页每页条数，不超过 MaxPageSize
+type PaginateConfig struct {
+	Home   int `yaml:"home"`
+	List   int `yaml:"list"` // 标签 / 分类页
+	Series int `yaml:"series"`
+}
+
+// ReadingConfig 字数统计与阅读时长估算
+type ReadingConfig struct {
+	CJKPerMin   int  `yaml:"cjk_per_min"`   // 每分钟阅读的 CJK 字符数
+	WordsPerMin int  `yaml:"words_per_min"` // 每分钟阅读的拉丁词数
+	ExcludeCode bool `yaml:"exclude_code"`  // 代码块 / 行内代码不计入字数
+}
+
+// SummaryConfig 自动摘要；正文里写了 <!--more--> 时以手动摘要为准
+type SummaryConfig struct {
+	Length int `yaml:"length"` // 自动摘要截取的字符数
+}
+
+// RelatedConfig 文章页底部的相关文章
+type RelatedConfig struct {
+	Count int `yaml:"count"` // 每篇文章展示的相关文章数，0 表示关闭
+}
+
+type FeedContent string
+
+const (
+	FeedFull    FeedContent = "full"
+	FeedSummary FeedContent = "summary"
+)
+
+type FeedConfig struct {
+	Content FeedContent `yaml:"content"` // full：输出完整正文；summary：只输出摘要
+	Limit   int         `yaml:"limit"`   // 最多输出多少篇
+}
+
+type RobotsConfig struct {
+	Disallow []string `yaml:"disallow"` // 追加到 "User-agent: *" 下的 Disallow 规则
+	Extra    string   `yaml:"extra"`    // 原样追加到 robots.txt 末尾
+}
+
 type AssetsConfig struct {
 }
 
@@ -62,6 +130,25 @@ func Default() Config {
 			IncludeDraft: false,
 			Now:          time.Now(),
 		},
+		Feed: FeedConfig{
+			Content: FeedFull,
+			Limit:   20,
+		},
+		Paginate: PaginateConfig{
+			Home:   20,
+			List:   20,
+			Series: 20,
+		},
+		Reading: ReadingConfig{
+			CJKPerMin:   300,
+			WordsPerMin: 200,
+		},
+		Summary: SummaryConfig{
+			Length: 150,
+		},
+		Related: RelatedConfig{
+			Count: 5,
+		},
 	}
 }
 
@@ -86,6 +173,12 @@ func (c Config) Validate() error {
 		ve.Add("site.sort_mode", "must be 'updated' or 'created'")
 	}
 
+	if tz := strings.TrimSpace(c.Site.TimeZone); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			ve.Add("site.time_zone", "unknown time zone: "+tz)
+		}
+	}
+
 	if strings.TrimSpace(c.Site.Theme) == "" {
 		ve.Add("site.themes", "must not be empty")
 	}
@@ -99,6 +192,9 @@ func (c Config) Validate() error {
 	if strings.TrimSpace(c.Build.ThemeDir) == "" {
 		ve.Add("build.theme_dir", "must not be empty")
 	}
+	if c.Build.Concurrency < 0 {
+		ve.Add("build.concurrency", "must not be negative")
+	}
 	if bp := strings.TrimSpace(c.Build.BasePath); bp != "" {
 		if !strings.HasPrefix(bp, "/") {
 			ve.Add("build.base_path", "must start with '/'")
@@ -108,6 +204,44 @@ func (c Config) Validate() error {
 		}
 	}
 
+	switch c.Feed.Content {
+	case "", FeedFull, FeedSummary:
+	default:
+		ve.Add("feed.content", "must be 'full' or 'summary'")
+	}
+	if c.Feed.Limit < 0 {
+		ve.Add("feed.limit", "must not be negative")
+	}
+	if c.Paginate.Home <= 0 || c.Paginate.Home > MaxPageSize {
+		ve.Add("paginate.home", "must be between 1 and "+strconv.Itoa(MaxPageSize))
+	}
+	if c.Paginate.List <= 0 || c.Paginate.List > MaxPageSize {
+		ve.Add("paginate.list", "must be between 1 and "+strconv.Itoa(MaxPageSize))
+	}
+	if c.Paginate.Series <= 0 || c.Paginate.Series > MaxPageSize {
+		ve.Add("paginate.series", "must be between 1 and "+strconv.Itoa(MaxPageSize))
+	}
+
+	if c.Reading.CJKPerMin <= 0 {
+		ve.Add("reading.cjk_per_min", "must be positive")
+	}
+	if c.Reading.WordsPerMin <= 0 {
+		ve.Add("reading.words_per_min", "must be positive")
+	}
+	if c.Summary.Length <= 0 {
+		ve.Add("summary.length", "must be positive")
+	}
+	if c.Related.Count < 0 {
+		ve.Add("related.count", "must not be negative")
+	}
+
+	for _, d := range c.Robots.Disallow {
+		if !strings.HasPrefix(strings.TrimSpace(d), "/") {
+			ve.Add("robots.disallow", "entries must start with '/'")
+			break
+		}
+	}
+
 	if ve.HasAny() {
 		return ve
 	}
@@ -21,15 +21,22 @@ type ArticleMeta struct {
 
 	Series      Series
 	Description string
-	Summary     string
+	Summary     string // 纯文本摘要：<!--more--> 之前的内容，或正文开头自动截取
+	Excerpt     string // <!--more--> 之前正文渲染出的 HTML，没有手动分隔时为空
 	Cover       string
 
-	Sticky int
-	Hidden bool
-	Draft  bool
+	Sticky  int
+	Hidden  bool
+	Draft   bool
+	NoIndex bool // 不希望被搜索引擎收录：不进 sitemap，页面输出 robots noindex
 
 	Aliases []string
 
+	// 发布窗口：Date 晚于构建时间的是定时文章，Expires 之后下线；由 ingest 按构建时间判定
+	Expires   time.Time
+	Scheduled bool
+	Expired   bool
+
 	// 由解析阶段填充（但仍属于 domain 数据）
 	WordCount int
 	ReadMin   int
@@ -49,11 +56,16 @@ type Heading struct {
 type BodyRef struct {
 	SourcePath  string
 	ContentHash string
+
+	// 页面包：源文件所在目录及其附带资源（相对 BundleDir），普通文章为空
+	BundleDir string
+	Assets    []string
 }
 
 type Article struct {
 	Meta ArticleMeta
 	Body BodyRef
+	Text string // 正文纯文本，只用于全文索引，不写入 meta
 }
 
 func (m *ArticleMeta) Normalize() {
@@ -0,0 +1,9 @@
+package content
+
+import "unicode"
+
+// IsCJK 判断是否是中日韩文字（汉字、平假名、片假名、谚文）；
+// 字数统计和全文索引都用它决定哪些字符逐字处理
+func IsCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,89 @@
+package site
+
+import (
+	"fmt"
+	"mygo/internal/domain/content"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// PostURL 返回文章详情页的站内路径：/post/YYYY/MM/DD/slug/
+func PostURL(m content.ArticleMeta) string {
+	d := m.Date
+	return fmt.Sprintf("/post/%04d/%02d/%02d/%s/",
+		d.Year(), int(d.Month()), d.Day(), m.Slug,
+	)
+}
+
+// ShortURL 返回文章短链的站内路径：/s/<id>/，没有短 ID 时返回空串
+func ShortURL(m content.ArticleMeta) string {
+	id := strings.TrimSpace(m.ShortID)
+	if id == "" {
+		return ""
+	}
+	return "/s/" + url.PathEscape(id) + "/"
+}
+
+// PageURL 返回分页列表第 page 页的地址：第一页就是 base 本身，其余为 base + "page/N/"
+func PageURL(base string, page int) string {
+	if page <= 1 {
+		return base
+	}
+	return strings.TrimRight(base, "/") + fmt.Sprintf("/page/%d/", page)
+}
+
+// AbsURL 把站内路径拼到 site_url 后面，得到 feed / sitemap 里需要的绝对地址
+func AbsURL(siteURL, path string) string {
+	return strings.TrimRight(siteURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// AliasPath 把 front matter 里的 alias 换算成旧地址的站内路径：
+// 带 "/" 的当作完整路径；否则视为改名前的 slug，沿用文章当前的日期。
+func AliasPath(m content.ArticleMeta, alias string) string {
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return ""
+	}
+	if strings.Contains(alias, "/") {
+		return CleanPath(alias)
+	}
+	old := m
+	old.Slug = alias
+	return PostURL(old)
+}
+
+// CleanPath 规范化站内路径：以 "/" 开头；没有扩展名的视为目录，以 "/" 结尾
+func CleanPath(p string) string {
+	p = path.Clean("/" + strings.TrimSpace(p))
+	if p == "/" {
+		return p
+	}
+	if path.Ext(p) == "" {
+		p += "/"
+	}
+	return p
+}
+
+// PathSegment 把标签 / 分类 / 系列名转成输出目录名：字母、数字、- 和 _ 之外的字符都换成 -
+func PathSegment(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "untitled"
+	}
+	repl := func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r
+		case r >= '0' && r <= '9':
+			return r
+		case r == '-' || r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}
+	return strings.Map(repl, s)
+}
@@ -0,0 +1,102 @@
+package feed
+
+import (
+	"encoding/xml"
+	"mygo/internal/domain/config"
+	"mygo/internal/domain/site"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomPerson  `xml:"author"`
+	Lang     string      `xml:"xml:lang,attr,omitempty"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+// Atom 生成 Atom 1.0 文档
+func Atom(sc config.SiteConfig, items []Item) ([]byte, error) {
+	home := site.AbsURL(sc.SiteURL, "/")
+	f := atomFeed{
+		Title:    sc.Title,
+		Subtitle: sc.Description,
+		ID:       home,
+		Lang:     sc.Language,
+		Links: []atomLink{
+			{Href: site.AbsURL(sc.SiteURL, AtomFile), Rel: "self", Type: "application/atom+xml"},
+			{Href: home, Rel: "alternate", Type: "text/html"},
+		},
+		// Atom 要求必须有 author，没配置时退回站点标题
+		Author: atomPerson{Name: sc.Author},
+	}
+	if f.Author.Name == "" {
+		f.Author.Name = sc.Title
+	}
+	updated := latestUpdated(items)
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+	f.Updated = updated.Format(time.RFC3339)
+
+	for _, it := range items {
+		m := it.Meta
+		e := atomEntry{
+			Title:     m.Title,
+			ID:        it.URL,
+			Link:      atomLink{Href: it.URL, Rel: "alternate", Type: "text/html"},
+			Published: m.Date.Format(time.RFC3339),
+			Updated:   m.Updated.Format(time.RFC3339),
+		}
+		if m.Category != "" {
+			e.Categories = append(e.Categories, atomCategory{Term: m.Category})
+		}
+		for _, t := range m.Tags {
+			e.Categories = append(e.Categories, atomCategory{Term: t})
+		}
+		switch {
+		case it.IsHTML && !it.IsExcerpt:
+			e.Content = &atomText{Type: "html", Body: it.Content}
+		case it.IsHTML:
+			e.Summary = &atomText{Type: "html", Body: it.Content}
+		case it.Content != "":
+			e.Summary = &atomText{Type: "text", Body: it.Content}
+		}
+		f.Entries = append(f.Entries, e)
+	}
+	return marshalXML(f)
+}
@@ -0,0 +1,125 @@
+package feed
+
+import (
+	"html"
+	"mygo/internal/domain/config"
+	"mygo/internal/domain/content"
+	"mygo/internal/domain/site"
+	"mygo/internal/index"
+	"mygo/internal/ingest"
+	"mygo/internal/render"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	RSSFile  = "rss.xml"
+	AtomFile = "atom.xml"
+)
+
+const (
+	defaultLimit        = 20
+	summaryExcerptRunes = 200
+)
+
+type Item struct {
+	Meta      content.ArticleMeta
+	URL       string // 绝对地址
+	Content   string // full 模式下是渲染后的 HTML，summary 模式下是手动摘要（HTML）或纯文本摘要
+	IsHTML    bool
+	IsExcerpt bool // Content 只是摘要而不是全文
+}
+
+type Options struct {
+	IncludeDraft  bool
+	IncludeFuture bool
+}
+
+// Collect 取出最新的 N 篇可见文章，按创建时间倒序（feed 里不考虑置顶）。
+// sources 为 slug -> Article，full 模式需要从源文件渲染正文，其余情况用 ingest 阶段得到的摘要和纯文本。
+func Collect(
+	st *index.Store,
+	md *render.MarkdownRenderer,
+	sources map[string]content.Article,
+	cfg config.Config,
+	opt Options,
+) ([]Item, error) {
+	metas, err := st.List(index.ListOptions{
+		Sort:          config.SortCreated,
+		All:           true,
+		IncludeDraft:  opt.IncludeDraft,
+		IncludeFuture: opt.IncludeFuture,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(metas, func(i, j int) bool {
+		return metas[i].Date.After(metas[j].Date)
+	})
+
+	limit := cfg.Feed.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if len(metas) > limit {
+		metas = metas[:limit]
+	}
+
+	items := make([]Item, 0, len(metas))
+	for _, m := range metas {
+		postPath := site.PostURL(m)
+		it := Item{
+			Meta: m,
+			URL:  site.AbsURL(cfg.Site.SiteURL, postPath),
+		}
+		a, ok := sources[m.Slug]
+		switch {
+		case cfg.Feed.Content != config.FeedSummary && ok:
+			body, err := ingest.ReadBody(a.Body.SourcePath)
+			if err != nil {
+				return nil, err
+			}
+			res, err := md.Render(body)
+			if err != nil {
+				return nil, err
+			}
+			it.Content = absoluteURLs(string(res.HTML), cfg.Site.SiteURL, postPath)
+			it.IsHTML = true
+		case m.Excerpt != "":
+			it.Content = absoluteURLs(m.Excerpt, cfg.Site.SiteURL, postPath)
+			it.IsHTML = true
+			it.IsExcerpt = true
+		case m.Description != "":
+			it.Content = m.Description
+		case m.Summary != "":
+			it.Content = m.Summary
+		case ok:
+			it.Content = render.TruncateRunes(a.Text, summaryExcerptRunes)
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// urlAttr 匹配 HTML 里的 href / src 属性；goldmark 输出双引号，正文里手写的 HTML 也可能用单引号
+var urlAttr = regexp.MustCompile(`(?i)(\s(?:href|src)\s*=\s*)("[^"]*"|'[^']*')`)
+
+// absoluteURLs 把 HTML 里的站内地址换成绝对地址：阅读器不知道文章页在哪，
+// 相对地址（按文章页 postPath 解析）和以 / 开头的站内路径都要拼上 site_url
+func absoluteURLs(s, siteURL, postPath string) string {
+	base := &url.URL{Path: postPath}
+	return urlAttr.ReplaceAllStringFunc(s, func(attr string) string {
+		m := urlAttr.FindStringSubmatch(attr)
+		quote, v := m[2][:1], html.UnescapeString(m[2][1:len(m[2])-1])
+		u, err := url.Parse(v)
+		if v == "" || err != nil || u.Scheme != "" || u.Host != "" {
+			return attr
+		}
+		if !strings.HasPrefix(u.Path, "/") {
+			u = base.ResolveReference(u)
+		}
+		return m[1] + quote + html.EscapeString(site.AbsURL(siteURL, u.String())) + quote
+	})
+}
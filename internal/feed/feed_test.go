@@ -0,0 +1,26 @@
+package feed
+
+import "testing"
+
+func TestAbsoluteURLs(t *testing.T) {
+	const post = "/post/2024/01/02/hello/"
+	tests := []struct {
+		site, in, want string
+	}{
+		{"https://example.com", `<img src="cover.png" alt="c">`,
+			`<img src="https://example.com/post/2024/01/02/hello/cover.png" alt="c">`},
+		{"https://example.com/", `<a href="/about/">a</a> <a href="../other/?a=1&amp;b=2#x">b</a>`,
+			`<a href="https://example.com/about/">a</a> <a href="https://example.com/post/2024/01/02/other/?a=1&amp;b=2#x">b</a>`},
+		// 部署在子路径下时站内路径同样拼在 site_url 后面
+		{"https://example.com/blog", `<a href="#top">t</a><IMG SRC='/img/a.png'>`,
+			`<a href="https://example.com/blog/post/2024/01/02/hello/#top">t</a><IMG SRC='https://example.com/blog/img/a.png'>`},
+		{"https://example.com", `<a href="https://x.org/a">x</a> <img src="//cdn.x.org/a.png"> <a href="mailto:a@b.c">m</a> <a href="">e</a>`,
+			`<a href="https://x.org/a">x</a> <img src="//cdn.x.org/a.png"> <a href="mailto:a@b.c">m</a> <a href="">e</a>`},
+		{"https://example.com", `<p data-src="a.png">href="a.png"</p>`, `<p data-src="a.png">href="a.png"</p>`},
+	}
+	for _, tt := range tests {
+		if got := absoluteURLs(tt.in, tt.site, post); got != tt.want {
+			t.Errorf("absoluteURLs(%q, %q) =\n%s\nwant\n%s", tt.in, tt.site, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"mygo/internal/domain/config"
+	"mygo/internal/domain/site"
+	"time"
+)
+
+type rssDoc struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Language      string      `xml:"language,omitempty"`
+	Generator     string      `xml:"generator"`
+	LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+	AtomLink      rssAtomLink `xml:"atom:link"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"` // <author> 要求填邮箱，作者名用 dc:creator
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS 生成 RSS 2.0 文档
+func RSS(sc config.SiteConfig, items []Item) ([]byte, error) {
+	ch := rssChannel{
+		Title:       sc.Title,
+		Link:        site.AbsURL(sc.SiteURL, "/"),
+		Description: sc.Description,
+		Language:    sc.Language,
+		Generator:   "mygo",
+		AtomLink: rssAtomLink{
+			Href: site.AbsURL(sc.SiteURL, RSSFile),
+			Rel:  "self",
+			Type: "application/rss+xml",
+		},
+	}
+	if ch.Description == "" {
+		ch.Description = sc.Subtitle
+	}
+	if latest := latestUpdated(items); !latest.IsZero() {
+		ch.LastBuildDate = latest.Format(time.RFC1123Z)
+	}
+
+	for _, it := range items {
+		m := it.Meta
+		cats := make([]string, 0, len(m.Tags)+1)
+		if m.Category != "" {
+			cats = append(cats, m.Category)
+		}
+		cats = append(cats, m.Tags...)
+		ch.Items = append(ch.Items, rssItem{
+			Title:       m.Title,
+			Link:        it.URL,
+			GUID:        rssGUID{IsPermaLink: true, Value: it.URL},
+			PubDate:     m.Date.Format(time.RFC1123Z),
+			Creator:     sc.Author,
+			Categories:  cats,
+			Description: it.Content,
+		})
+	}
+
+	return marshalXML(rssDoc{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: ch,
+	})
+}
+
+func latestUpdated(items []Item) time.Time {
+	var t time.Time
+	for _, it := range items {
+		if it.Meta.Updated.After(t) {
+			t = it.Meta.Updated
+		}
+	}
+	return t
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
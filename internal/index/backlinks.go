@@ -0,0 +1,80 @@
+package index
+
+import (
+	"bytes"
+	"encoding/json"
+	bolt "go.etcd.io/bbolt"
+	"mygo/internal/domain/content"
+	"sort"
+	"strings"
+)
+
+// 反向链接：ingest 已把指向站内文章的 OutLinks 换成 slug，这里按 "被链接的 slug 0x00 来源 slug"
+// 写入 bBacklinks，随来源文章一起写入 / 删除。被链接的文章删掉后记录保留，文章恢复时仍然有效
+
+func backlinkKey(target, source string) []byte {
+	return []byte(target + "\x00" + source)
+}
+
+// isSlugLink 判断 OutLinks 里的一项是否是站内文章的 slug。没能解析的链接保留原样，通常带有 / : ? # 之一；
+// 不带这些字符的相对链接恰好与某个 slug 同名时会多出一条记录，可以接受
+func isSlugLink(l string) bool {
+	return l != "" && !strings.ContainsAny(l, "/:?#")
+}
+
+func putBacklinks(tx *bolt.Tx, m content.ArticleMeta) error {
+	b := tx.Bucket(bBacklinks)
+	for _, l := range m.OutLinks {
+		if !isSlugLink(l) {
+			continue
+		}
+		if err := b.Put(backlinkKey(l, m.Slug), []byte{1}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteBacklinks(tx *bolt.Tx, m content.ArticleMeta) error {
+	b := tx.Bucket(bBacklinks)
+	for _, l := range m.OutLinks {
+		if !isSlugLink(l) {
+			continue
+		}
+		if err := b.Delete(backlinkKey(l, m.Slug)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Backlinks 返回链接到 slug 的文章，按发布时间从新到旧排列，并按 opt 过滤草稿 / 定时文章
+func (s *Store) Backlinks(slug string, opt ListOptions) ([]content.ArticleMeta, error) {
+	var out []content.ArticleMeta
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b, metaB := tx.Bucket(bBacklinks), tx.Bucket(bMeta)
+		if b == nil || metaB == nil {
+			return nil
+		}
+		prefix := backlinkKey(slug, "")
+		c := b.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			mv := metaB.Get(k[len(prefix):])
+			if mv == nil {
+				continue
+			}
+			var m content.ArticleMeta
+			if err := json.Unmarshal(mv, &m); err != nil {
+				return err
+			}
+			if visible(m, opt) {
+				out = append(out, m)
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Date.After(out[j].Date)
+	})
+	return out, err
+}
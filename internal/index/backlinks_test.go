@@ -0,0 +1,124 @@
+package index
+
+import (
+	"mygo/internal/domain/content"
+	"mygo/internal/ingest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// backlinkSources 中每篇 from-* 用一种 resolveOutLinks 认得的写法链接到 target
+var backlinkSources = map[string]string{
+	"target.md": `---
+title: Target
+date: 2024-01-10
+aliases: [old-target, /archive/target-page/]
+short: tgt
+---
+[self](/post/2024/01/10/target/) [top](#top)
+`,
+	"from-md.md":           datedSource("2024-01-01", "[t](target.md)"),
+	"sub/from-rel.md":      datedSource("2024-01-02", "[t](../target.md)"),
+	"from-post.md":         datedSource("2024-01-03", "[t](/post/2024/01/10/target/)"),
+	"from-abs.md":          datedSource("2024-01-04", "[t](https://example.com/post/2024/01/10/target/#intro)"),
+	"from-alias.md":        datedSource("2024-01-05", "[t](/post/2024/01/10/old-target/)"),
+	"from-alias-path.md":   datedSource("2024-01-06", "[t](/archive/target-page/)"),
+	"from-short.md":        datedSource("2024-01-07", "see /s/tgt/ or [t](/s/tgt/)"),
+	"from-relative-url.md": datedSource("2024-01-08", "[t](../../10/target/)"),
+	"external.md":          datedSource("2024-01-09", "[x](https://other.org/post/2024/01/10/target/) [y](/post/2099/01/01/nothing/)"),
+	"draft.md":             "---\ndate: 2024-01-11\ndraft: true\n---\n[t](target.md)\n",
+}
+
+func datedSource(date, body string) string {
+	return "---\ndate: " + date + "\n---\n" + body + "\n"
+}
+
+func ingestBacklinkSite(t *testing.T) []content.Article {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range backlinkSources {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	arts, _, err := ingest.Ingest(ingest.Options{
+		SourceDir: dir,
+		SiteURL:   "https://example.com",
+		Now:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Location:  time.UTC,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return arts
+}
+
+// 按发布时间从新到旧
+var allBacklinks = []string{
+	"from-relative-url", "from-short", "from-alias-path", "from-alias",
+	"from-abs", "from-post", "from-rel", "from-md",
+}
+
+func backlinkSlugs(t *testing.T, s *Store, slug string, opt ListOptions) []string {
+	t.Helper()
+	got, err := s.Backlinks(slug, opt)
+	if err != nil {
+		t.Fatalf("Backlinks(%s): %v", slug, err)
+	}
+	return slugsOf(got)
+}
+
+func TestBacklinksLinkForms(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.Rebuild(ingestBacklinkSite(t), RebuildOptions{IncludeDraft: true}); err != nil {
+		t.Fatal(err)
+	}
+	assertStrings(t, "backlinks", backlinkSlugs(t, s, "target", ListOptions{}), allBacklinks)
+	assertStrings(t, "with drafts", backlinkSlugs(t, s, "target", ListOptions{IncludeDraft: true}),
+		append([]string{"draft"}, allBacklinks...))
+	assertStrings(t, "unresolved links", backlinkSlugs(t, s, "nothing", ListOptions{}), nil)
+}
+
+func TestBacklinksIncremental(t *testing.T) {
+	s := openTestStore(t)
+	arts := ingestBacklinkSite(t)
+	opt := RebuildOptions{}
+	if err := s.Rebuild(arts, opt); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range arts {
+		if arts[i].Meta.Slug == "from-md" {
+			arts[i].Meta.OutLinks = nil
+			if err := s.Upsert(&arts[i], opt); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := s.Delete("from-short"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"from-relative-url", "from-alias-path", "from-alias", "from-abs", "from-post", "from-rel"}
+	assertStrings(t, "backlinks", backlinkSlugs(t, s, "target", ListOptions{}), want)
+
+	// 被链接的文章删掉再恢复，反向链接仍然有效
+	var target content.Article
+	for _, a := range arts {
+		if a.Meta.Slug == "target" {
+			target = a
+		}
+	}
+	if err := s.Delete("target"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Upsert(&target, opt); err != nil {
+		t.Fatal(err)
+	}
+	assertStrings(t, "after restoring target", backlinkSlugs(t, s, "target", ListOptions{}), want)
+}
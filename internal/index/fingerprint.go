@@ -0,0 +1,50 @@
+package index
+
+import (
+	"encoding/json"
+	bolt "go.etcd.io/bbolt"
+	"mygo/internal/domain/build"
+)
+
+// LoadFingerprints 读出上一次构建记录的所有输出页面 fingerprint
+func (s *Store) LoadFingerprints() (map[string]build.Fingerprint, error) {
+	out := make(map[string]build.Fingerprint)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bFingerprint)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var fp build.Fingerprint
+			if err := json.Unmarshal(v, &fp); err != nil {
+				// 损坏的记录直接忽略，对应页面会被重新渲染
+				return nil
+			}
+			out[string(k)] = fp
+			return nil
+		})
+	})
+	return out, err
+}
+
+// SaveFingerprints 用本次构建的结果整体替换 fingerprint bucket，
+// 这样已经不再输出的页面也不会残留旧记录
+func (s *Store) SaveFingerprints(fps map[string]build.Fingerprint) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		_ = tx.DeleteBucket(bFingerprint)
+		b, err := tx.CreateBucket(bFingerprint)
+		if err != nil {
+			return err
+		}
+		for path, fp := range fps {
+			v, err := json.Marshal(fp)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(path), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
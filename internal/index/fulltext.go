@@ -0,0 +1,396 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	bolt "go.etcd.io/bbolt"
+	"html"
+	"math"
+	"mygo/internal/domain/content"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 全文索引：标题和正文纯文本切成 CJK 二元组与拉丁词，倒排表随文章一起写入 / 删除，
+// 查询时对所有词取交集，按 BM25 打分
+
+const (
+	maxTermBytes = 64  // 更长的拉丁 "词" 多半是 URL 或哈希，不进索引
+	titleBoost   = 3   // 标题里出现一次按正文出现三次计
+	snippetRunes = 120 // 摘录片段的长度
+	bm25K1       = 1.2
+	bm25B        = 0.75
+)
+
+// ftDoc 记录一篇文章的词数和出现过的词，删除时据此清理倒排表
+type ftDoc struct {
+	Len   int      `json:"len"`
+	Terms []string `json:"terms"`
+}
+
+// token 是切分出的一个词，start / end 是它在原文中的字节偏移
+type token struct {
+	term       string
+	start, end int
+}
+
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !content.IsCJK(r)
+}
+
+// tokenize 切分文本：连续的 CJK 字符切成相邻二元组（只有一个字时保留单字），
+// 其余字母 / 数字按词切分并转成小写
+func tokenize(s string) []token {
+	var out []token
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case content.IsCJK(r):
+			var starts []int
+			j := i
+			for j < len(s) {
+				r2, sz := utf8.DecodeRuneInString(s[j:])
+				if !content.IsCJK(r2) {
+					break
+				}
+				starts = append(starts, j)
+				j += sz
+			}
+			starts = append(starts, j)
+			if len(starts) == 2 {
+				out = append(out, token{s[i:j], i, j})
+			}
+			for k := 0; k+2 < len(starts); k++ {
+				out = append(out, token{s[starts[k]:starts[k+2]], starts[k], starts[k+2]})
+			}
+			i = j
+		case isWordRune(r):
+			j := i
+			for j < len(s) {
+				r2, sz := utf8.DecodeRuneInString(s[j:])
+				if !isWordRune(r2) {
+					break
+				}
+				j += sz
+			}
+			if j-i <= maxTermBytes {
+				out = append(out, token{strings.ToLower(s[i:j]), i, j})
+			}
+			i = j
+		default:
+			i += size
+		}
+	}
+	return out
+}
+
+func postingKey(term, slug string) []byte {
+	return []byte(term + "\x00" + slug)
+}
+
+// putFullText 为文章写入倒排表；调用方保证 slug 下没有旧数据
+func putFullText(tx *bolt.Tx, a content.Article) error {
+	slug := a.Meta.Slug
+	tf := make(map[string][2]int) // term -> {标题词频, 正文词频}
+	n := 0
+	for field, src := range []string{a.Meta.Title, a.Text} {
+		for _, t := range tokenize(src) {
+			c := tf[t.term]
+			c[field]++
+			tf[t.term] = c
+			n++
+		}
+	}
+
+	doc := ftDoc{Len: n, Terms: make([]string, 0, len(tf))}
+	postB := tx.Bucket(bFTPosting)
+	for term, c := range tf {
+		doc.Terms = append(doc.Terms, term)
+		// bolt 在事务提交前一直引用传给 Put 的切片，每条记录要用自己的缓冲区
+		v := binary.AppendUvarint(make([]byte, 0, 2*binary.MaxVarintLen64), uint64(c[0]))
+		v = binary.AppendUvarint(v, uint64(c[1]))
+		if err := postB.Put(postingKey(term, slug), v); err != nil {
+			return err
+		}
+	}
+	sort.Strings(doc.Terms)
+
+	dv, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(bFTDoc).Put([]byte(slug), dv); err != nil {
+		return err
+	}
+	return tx.Bucket(bFTText).Put([]byte(slug), []byte(a.Text))
+}
+
+// deleteFullText 按 ftDoc 记录的词清理倒排表
+func deleteFullText(tx *bolt.Tx, slug string) error {
+	docB := tx.Bucket(bFTDoc)
+	if v := docB.Get([]byte(slug)); v != nil {
+		var doc ftDoc
+		if err := json.Unmarshal(v, &doc); err != nil {
+			return err
+		}
+		postB := tx.Bucket(bFTPosting)
+		for _, term := range doc.Terms {
+			if err := postB.Delete(postingKey(term, slug)); err != nil {
+				return err
+			}
+		}
+		if err := docB.Delete([]byte(slug)); err != nil {
+			return err
+		}
+	}
+	return tx.Bucket(bFTText).Delete([]byte(slug))
+}
+
+// SearchOptions 控制全文搜索；草稿 / 定时文章的可见性与列表查询相同
+type SearchOptions struct {
+	Limit         int // 最多返回多少条，<=0 时为 20
+	IncludeDraft  bool
+	IncludeFuture bool
+}
+
+// SearchHit 是一条搜索结果
+type SearchHit struct {
+	Meta    content.ArticleMeta
+	Score   float64
+	Snippet Snippet
+}
+
+// Snippet 是命中位置附近的一段正文，Marks 是命中词在 Text 中的字节区间（有序、不重叠）
+type Snippet struct {
+	Text  string
+	Marks [][2]int
+}
+
+// Format 用 open / close 包裹命中词，其余文本经过 esc 处理（esc 为 nil 时原样输出）
+func (s Snippet) Format(open, close string, esc func(string) string) string {
+	if esc == nil {
+		esc = func(s string) string { return s }
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range s.Marks {
+		b.WriteString(esc(s.Text[last:m[0]]))
+		b.WriteString(open)
+		b.WriteString(esc(s.Text[m[0]:m[1]]))
+		b.WriteString(close)
+		last = m[1]
+	}
+	b.WriteString(esc(s.Text[last:]))
+	return b.String()
+}
+
+// HTML 返回转义后的片段，命中词用 <mark> 包裹
+func (s Snippet) HTML() string {
+	return s.Format("<mark>", "</mark>", html.EscapeString)
+}
+
+// queryTerm 是查询里的一个词；单个 CJK 字按前缀匹配，能命中以它开头的二元组
+type queryTerm struct {
+	term   string
+	prefix bool
+}
+
+func (q queryTerm) matches(term string) bool {
+	if q.prefix {
+		return strings.HasPrefix(term, q.term)
+	}
+	return term == q.term
+}
+
+func parseQuery(query string) []queryTerm {
+	seen := make(map[string]bool)
+	var out []queryTerm
+	for _, t := range tokenize(query) {
+		if seen[t.term] {
+			continue
+		}
+		seen[t.term] = true
+		r, size := utf8.DecodeRuneInString(t.term)
+		out = append(out, queryTerm{term: t.term, prefix: size == len(t.term) && content.IsCJK(r)})
+	}
+	return out
+}
+
+// Search 在全文索引里查找同时包含查询中所有词的文章，按相关度从高到低返回
+func (s *Store) Search(query string, opt SearchOptions) ([]SearchHit, error) {
+	if opt.Limit <= 0 {
+		opt.Limit = 20
+	}
+	terms := parseQuery(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	visOpt := ListOptions{IncludeDraft: opt.IncludeDraft, IncludeFuture: opt.IncludeFuture}
+
+	var hits []SearchHit
+	err := s.db.View(func(tx *bolt.Tx) error {
+		docB, postB, metaB, textB := tx.Bucket(bFTDoc), tx.Bucket(bFTPosting), tx.Bucket(bMeta), tx.Bucket(bFTText)
+		if docB == nil || postB == nil || metaB == nil || textB == nil {
+			return nil
+		}
+
+		lens := make(map[string]int)
+		total := 0
+		err := docB.ForEach(func(k, v []byte) error {
+			var doc ftDoc
+			if err := json.Unmarshal(v, &doc); err != nil {
+				return err
+			}
+			lens[string(k)] = doc.Len
+			total += doc.Len
+			return nil
+		})
+		if err != nil || len(lens) == 0 {
+			return err
+		}
+		n := float64(len(lens))
+		avg := float64(total) / n
+
+		var scores map[string]float64
+		for i, q := range terms {
+			tf := postings(postB, q)
+			idf := math.Log(1 + (n-float64(len(tf))+0.5)/(float64(len(tf))+0.5))
+			next := make(map[string]float64, len(tf))
+			for slug, f := range tf {
+				if i > 0 {
+					if _, ok := scores[slug]; !ok {
+						continue
+					}
+				}
+				norm := 1 - bm25B + bm25B*float64(lens[slug])/avg
+				next[slug] = scores[slug] + idf*f*(bm25K1+1)/(f+bm25K1*norm)
+			}
+			scores = next
+			if len(scores) == 0 {
+				return nil
+			}
+		}
+
+		for slug, score := range scores {
+			v := metaB.Get([]byte(slug))
+			if v == nil {
+				continue
+			}
+			var m content.ArticleMeta
+			if err := json.Unmarshal(v, &m); err != nil {
+				return err
+			}
+			if !visible(m, visOpt) {
+				continue
+			}
+			hits = append(hits, SearchHit{Meta: m, Score: score})
+		}
+		sort.Slice(hits, func(i, j int) bool {
+			if hits[i].Score != hits[j].Score {
+				return hits[i].Score > hits[j].Score
+			}
+			if !hits[i].Meta.Date.Equal(hits[j].Meta.Date) {
+				return hits[i].Meta.Date.After(hits[j].Meta.Date)
+			}
+			return hits[i].Meta.Slug < hits[j].Meta.Slug
+		})
+		if len(hits) > opt.Limit {
+			hits = hits[:opt.Limit]
+		}
+		for i := range hits {
+			hits[i].Snippet = makeSnippet(string(textB.Get([]byte(hits[i].Meta.Slug))), terms)
+		}
+		return nil
+	})
+	return hits, err
+}
+
+// postings 读出一个查询词命中的文章及加权词频；前缀匹配时把各个词的词频相加
+func postings(b *bolt.Bucket, q queryTerm) map[string]float64 {
+	out := make(map[string]float64)
+	prefix := []byte(q.term)
+	if !q.prefix {
+		prefix = append(prefix, 0)
+	}
+	c := b.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		i := bytes.IndexByte(k, 0)
+		if i < 0 {
+			continue
+		}
+		out[string(k[i+1:])] += postingTF(v)
+	}
+	return out
+}
+
+// postingTF 解出倒排表记录的词频，标题中的出现按 titleBoost 加权
+func postingTF(v []byte) float64 {
+	title, n := binary.Uvarint(v)
+	if n <= 0 {
+		return 0
+	}
+	body, _ := binary.Uvarint(v[n:])
+	return float64(titleBoost*title + body)
+}
+
+// makeSnippet 以第一个命中词为中心截取一段正文并标出其中所有命中词；没有命中时取开头
+func makeSnippet(text string, terms []queryTerm) Snippet {
+	var marks [][2]int
+	for _, t := range tokenize(text) {
+		hit := false
+		for _, q := range terms {
+			if q.matches(t.term) {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			continue
+		}
+		// CJK 二元组彼此重叠，合并成连续区间
+		if len(marks) > 0 && t.start <= marks[len(marks)-1][1] {
+			if t.end > marks[len(marks)-1][1] {
+				marks[len(marks)-1][1] = t.end
+			}
+			continue
+		}
+		marks = append(marks, [2]int{t.start, t.end})
+	}
+
+	start := 0
+	if len(marks) > 0 {
+		// 命中词前保留约三分之一的上下文
+		start = marks[0][0]
+		for back := 0; start > 0 && back < snippetRunes/3; back++ {
+			_, size := utf8.DecodeLastRuneInString(text[:start])
+			start -= size
+		}
+	}
+	end := start
+	for n := 0; end < len(text) && n < snippetRunes; n++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+
+	sn := Snippet{Text: text[start:end]}
+	for _, m := range marks {
+		if m[0] < start || m[1] > end {
+			continue
+		}
+		sn.Marks = append(sn.Marks, [2]int{m[0] - start, m[1] - start})
+	}
+	if start > 0 {
+		sn.Text = "…" + sn.Text
+		for i := range sn.Marks {
+			sn.Marks[i][0] += len("…")
+			sn.Marks[i][1] += len("…")
+		}
+	}
+	if end < len(text) {
+		sn.Text += "…"
+	}
+	return sn
+}
@@ -0,0 +1,176 @@
+package index
+
+import (
+	"mygo/internal/domain/content"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"--- ... !!", nil},
+		{"Hello, World!", []string{"hello", "world"}},
+		{"GoLang GOLANG golang", []string{"golang", "golang", "golang"}},
+		{"Go1.21 isn't e.g. v2", []string{"go1", "21", "isn", "t", "e", "g", "v2"}},
+		{"ÉCOLE Café", []string{"école", "café"}},
+		{"全文索引", []string{"全文", "文索", "索引"}},
+		{"和", []string{"和"}},
+		{"用Go写博客", []string{"用", "go", "写博", "博客"}},
+		{"中文，标点。English", []string{"中文", "标点", "english"}},
+		{"ひらがな", []string{"ひら", "らが", "がな"}},
+		{"short " + strings.Repeat("x", maxTermBytes+1) + " tail", []string{"short", "tail"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, tok := range tokenize(tt.in) {
+			got = append(got, tok.term)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeOffsets(t *testing.T) {
+	s := "Go 语言!"
+	want := []token{{"go", 0, 2}, {"语言", 3, 9}}
+	if got := tokenize(s); !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize(%q) = %v, want %v", s, got, want)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	got := parseQuery("搜 Go go 全文")
+	want := []queryTerm{{"搜", true}, {"go", false}, {"全文", false}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseQuery = %v, want %v", got, want)
+	}
+}
+
+func searchStore(t *testing.T) *Store {
+	t.Helper()
+	s := openTestStore(t)
+	arts := []content.Article{
+		testArticle("alpha", 1, func(a *content.Article) {
+			a.Meta.Title = "Go concurrency"
+			a.Text = "Alpha explains goroutines in go."
+		}),
+		testArticle("beta", 2, func(a *content.Article) {
+			a.Meta.Title = "Web notes"
+			a.Text = "Beta body mentions go and web"
+		}),
+		testArticle("gamma", 3, func(a *content.Article) {
+			a.Meta.Title = "Rust"
+			a.Text = "Gamma has nothing relevant"
+		}),
+		testArticle("wip", 4, func(a *content.Article) {
+			a.Meta.Title = "Unfinished"
+			a.Meta.Draft = true
+			a.Text = "a go draft"
+		}),
+		testArticle("cjk", 5, func(a *content.Article) {
+			a.Meta.Title = "全文搜索"
+			a.Text = "介绍中文全文索引的实现"
+		}),
+	}
+	if err := s.Rebuild(arts, RebuildOptions{IncludeDraft: true}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func hitSlugs(hits []SearchHit) []string {
+	var out []string
+	for _, h := range hits {
+		out = append(out, h.Meta.Slug)
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	s := searchStore(t)
+	tests := []struct {
+		query string
+		opt   SearchOptions
+		want  []string
+	}{
+		// 标题里的命中加权，alpha 排在 beta 前面；草稿默认不出现
+		{"go", SearchOptions{}, []string{"alpha", "beta"}},
+		{"GO", SearchOptions{}, []string{"alpha", "beta"}},
+		// 正文更短的文章词频占比更高
+		{"go", SearchOptions{IncludeDraft: true}, []string{"alpha", "wip", "beta"}},
+		{"go", SearchOptions{Limit: 1}, []string{"alpha"}},
+		// 所有词都要命中
+		{"go web", SearchOptions{}, []string{"beta"}},
+		{"go rust", SearchOptions{}, nil},
+		{"全文", SearchOptions{}, []string{"cjk"}},
+		{"全文索引", SearchOptions{}, []string{"cjk"}},
+		{"索", SearchOptions{}, []string{"cjk"}},
+		{"索全", SearchOptions{}, nil},
+		{"!!", SearchOptions{}, nil},
+	}
+	for _, tt := range tests {
+		hits, err := s.Search(tt.query, tt.opt)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tt.query, err)
+		}
+		if got := hitSlugs(hits); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q, %+v) = %q, want %q", tt.query, tt.opt, got, tt.want)
+		}
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	s := searchStore(t)
+	tests := []struct {
+		query, slug, want string
+	}{
+		{"go", "beta", "Beta body mentions [go] and web"},
+		{"web GO", "beta", "Beta body mentions [go] and [web]"},
+		{"go", "alpha", "Alpha explains goroutines in [go]."},
+		{"全文索引", "cjk", "介绍中文[全文索引]的实现"},
+		// 单字按前缀匹配，标出它开头的整个二元组
+		{"索", "cjk", "介绍中文全文[索引]的实现"},
+	}
+	for _, tt := range tests {
+		hits, err := s.Search(tt.query, SearchOptions{})
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tt.query, err)
+		}
+		var got string
+		found := false
+		for _, h := range hits {
+			if h.Meta.Slug == tt.slug {
+				got, found = h.Snippet.Format("[", "]", nil), true
+			}
+		}
+		if !found {
+			t.Errorf("Search(%q) did not return %s", tt.query, tt.slug)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Search(%q) snippet of %s = %q, want %q", tt.query, tt.slug, got, tt.want)
+		}
+	}
+}
+
+func TestSnippetHTML(t *testing.T) {
+	sn := makeSnippet("a <b> & go", []queryTerm{{term: "go"}})
+	if got, want := sn.HTML(), "a &lt;b&gt; &amp; <mark>go</mark>"; got != want {
+		t.Errorf("HTML = %q, want %q", got, want)
+	}
+
+	long := strings.Repeat("word ", 100) + "needle " + strings.Repeat("word ", 100)
+	sn = makeSnippet(long, []queryTerm{{term: "needle"}})
+	if !strings.HasPrefix(sn.Text, "…") || !strings.HasSuffix(sn.Text, "…") {
+		t.Errorf("long snippet not trimmed on both sides: %q", sn.Text)
+	}
+	if !strings.Contains(sn.Format("[", "]", nil), "[needle]") {
+		t.Errorf("long snippet lost the hit: %q", sn.Text)
+	}
+}
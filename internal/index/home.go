@@ -19,10 +19,18 @@ type HomeItem struct {
 	Series *SeriesSummary
 }
 
-func (s *Store) HomeItems(opt ListOptions) ([]HomeItem, error) {
-	metas, err := s.List(opt)
+// HomeItems 返回首页第 opt.Page 页的条目，以及分组后的条目总数。
+// 同一系列的文章折叠成一个条目，所以必须先对全量文章分组排序，再做分页。
+func (s *Store) HomeItems(opt ListOptions) ([]HomeItem, int, error) {
+	opt.Page, opt.Size = normalizePaging(opt)
+	metas, err := s.List(ListOptions{
+		Sort:          opt.Sort,
+		All:           true,
+		IncludeDraft:  opt.IncludeDraft,
+		IncludeFuture: opt.IncludeFuture,
+	})
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	seenSeries := make(map[string]struct{})
 	var items []HomeItem
@@ -78,8 +86,8 @@ func (s *Store) HomeItems(opt ListOptions) ([]HomeItem, error) {
 				tj = aj.Meta.Updated.UnixNano()
 			}
 		} else {
-			si = ai.Series.MaxSticky
-			ti = ai.Series.LatestUpdated.UnixNano()
+			sj = aj.Series.MaxSticky
+			tj = aj.Series.LatestUpdated.UnixNano()
 		}
 
 		if si != sj {
@@ -87,5 +95,15 @@ func (s *Store) HomeItems(opt ListOptions) ([]HomeItem, error) {
 		}
 		return ti > tj
 	})
-	return items, nil
+
+	total := len(items)
+	start := (opt.Page - 1) * opt.Size
+	if start >= total {
+		return nil, total, nil
+	}
+	end := start + opt.Size
+	if end > total {
+		end = total
+	}
+	return items[start:end], total, nil
 }
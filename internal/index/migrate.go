@@ -0,0 +1,169 @@
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"mygo/internal/domain/content"
+	"strconv"
+)
+
+// SchemaVersion 是当前代码使用的索引布局版本。
+// 改动 meta 的编码或 key 的打包方式时加一，并在 migrations 里注册对应的迁移
+const SchemaVersion = 4
+
+// ErrSchema 表示索引文件的布局无法升级到 SchemaVersion
+var ErrSchema = errors.New("incompatible index schema")
+
+var schemaKey = []byte("version")
+
+// migration 把索引从 to-1 版升级到 to 版，在 Open 时与版本号写入处于同一个事务里
+type migration struct {
+	to   int
+	name string
+	up   func(tx *bolt.Tx) error
+}
+
+var migrations = []migration{
+	{to: 1, name: "drop the unused idx bucket and check meta encoding", up: migrateV1},
+	{to: 2, name: "add full-text buckets", up: migrateV2},
+	{to: 3, name: "add related posts bucket", up: migrateV3},
+	{to: 4, name: "build backlinks from stored out-links", up: migrateV4},
+}
+
+// migrate 依次执行尚未应用的迁移；全部成功才写入新版本号，失败时文件保持原样
+func migrate(db *bolt.DB) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		v, err := schemaVersion(tx)
+		if err != nil {
+			return err
+		}
+		if v > SchemaVersion {
+			return fmt.Errorf("%w: version %d is newer than %d supported by this mygo", ErrSchema, v, SchemaVersion)
+		}
+		if v == 0 && isEmpty(tx) {
+			// 新建的索引直接是最新版本
+			return setSchemaVersion(tx, SchemaVersion)
+		}
+		for _, m := range migrations {
+			if m.to <= v {
+				continue
+			}
+			if err := m.up(tx); err != nil {
+				return fmt.Errorf("%w: migrate v%d -> v%d (%s): %v", ErrSchema, m.to-1, m.to, m.name, err)
+			}
+			v = m.to
+		}
+		return setSchemaVersion(tx, v)
+	})
+}
+
+// schemaVersion 读出索引的版本号，没有版本记录的旧文件视为 0
+func schemaVersion(tx *bolt.Tx) (int, error) {
+	b := tx.Bucket(bSchema)
+	if b == nil {
+		return 0, nil
+	}
+	raw := b.Get(schemaKey)
+	if raw == nil {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(string(raw))
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("%w: bad version %q", ErrSchema, raw)
+	}
+	return v, nil
+}
+
+func setSchemaVersion(tx *bolt.Tx, v int) error {
+	b, err := tx.CreateBucketIfNotExists(bSchema)
+	if err != nil {
+		return err
+	}
+	return b.Put(schemaKey, []byte(strconv.Itoa(v)))
+}
+
+func isEmpty(tx *bolt.Tx) bool {
+	k, _ := tx.Cursor().First()
+	return k == nil
+}
+
+// migrateV1：v0 是没有版本记录的布局，多了一个从未使用的 idx bucket。
+// 这里同时确认 meta 都能解码、时间索引里的 slug 都有对应 meta，否则无法保证后续查询正确
+func migrateV1(tx *bolt.Tx) error {
+	if tx.Bucket([]byte("idx")) != nil {
+		if err := tx.DeleteBucket([]byte("idx")); err != nil {
+			return err
+		}
+	}
+	metaB := tx.Bucket(bMeta)
+	if metaB == nil {
+		return nil
+	}
+	err := metaB.ForEach(func(k, v []byte) error {
+		var m content.ArticleMeta
+		if err := json.Unmarshal(v, &m); err != nil {
+			return fmt.Errorf("meta %q: %w", k, err)
+		}
+		if m.Slug != string(k) {
+			return fmt.Errorf("meta %q has slug %q", k, m.Slug)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range [][]byte{bIdxUpdated, bIdxCreated} {
+		b := tx.Bucket(name)
+		if b == nil {
+			continue
+		}
+		err := b.ForEach(func(k, _ []byte) error {
+			slug := slugFromStickyTimeSlugKey(k)
+			if slug == "" || metaB.Get([]byte(slug)) == nil {
+				return fmt.Errorf("%s key %x does not point to an article", name, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateV2：新增全文索引。旧文章的正文在这里拿不到，建好空 bucket 即可：
+// Sync 比较正文时发现全文索引里没有记录，会把这些文章逐篇重新写入
+func migrateV2(tx *bolt.Tx) error {
+	for _, name := range [][]byte{bFTDoc, bFTText, bFTPosting} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateV3：新增相关文章。下次 Sync 发现没有结果时会整体计算一次
+func migrateV3(tx *bolt.Tx) error {
+	_, err := tx.CreateBucketIfNotExists(bRelated)
+	return err
+}
+
+// migrateV4：新增反向链接。meta 里已经保存了归一化后的 OutLinks，直接据此建立
+func migrateV4(tx *bolt.Tx) error {
+	if _, err := tx.CreateBucketIfNotExists(bBacklinks); err != nil {
+		return err
+	}
+	metaB := tx.Bucket(bMeta)
+	if metaB == nil {
+		return nil
+	}
+	return metaB.ForEach(func(k, v []byte) error {
+		var m content.ArticleMeta
+		if err := json.Unmarshal(v, &m); err != nil {
+			return fmt.Errorf("meta %q: %w", k, err)
+		}
+		return putBacklinks(tx, m)
+	})
+}
@@ -0,0 +1,222 @@
+package index
+
+import (
+	"encoding/json"
+	"errors"
+	bolt "go.etcd.io/bbolt"
+	"mygo/internal/domain/content"
+	"path/filepath"
+	"testing"
+)
+
+// seedDB 用 fill 直接写出一个旧布局的索引文件
+func seedDB(t *testing.T, fill func(tx *bolt.Tx) error) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "index.db")
+	db, err := openDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Update(fill); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// putLegacyMetas 按 v0 的布局写入 meta 和时间索引，并留下早已不用的 idx bucket
+func putLegacyMetas(tx *bolt.Tx, metas ...content.ArticleMeta) error {
+	if _, err := tx.CreateBucketIfNotExists([]byte("idx")); err != nil {
+		return err
+	}
+	for _, name := range [][]byte{bMeta, bIdxUpdated, bIdxCreated} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	for _, m := range metas {
+		mb, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(bMeta).Put([]byte(m.Slug), mb); err != nil {
+			return err
+		}
+		uKey := makeStickyTimeSlugKey(m.Sticky, m.Updated.UnixNano(), m.Slug)
+		if err := tx.Bucket(bIdxUpdated).Put(uKey, []byte{1}); err != nil {
+			return err
+		}
+		cKey := makeStickyTimeSlugKey(m.Sticky, m.Date.UnixNano(), m.Slug)
+		if err := tx.Bucket(bIdxCreated).Put(cKey, []byte{1}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func legacyMetas() []content.ArticleMeta {
+	a := testArticle("a", 1, func(a *content.Article) { a.Meta.OutLinks = []string{"b", "/tags/go/"} })
+	b := testArticle("b", 2, nil)
+	return []content.ArticleMeta{a.Meta, b.Meta}
+}
+
+func storeVersion(t *testing.T, s *Store) int {
+	t.Helper()
+	var v int
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		v, err = schemaVersion(tx)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func openSeeded(t *testing.T, path string) *Store {
+	t.Helper()
+	s, err := Open(OpenOptions{Path: path})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestOpenNewStampsVersion(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.ResetReason(); err != nil {
+		t.Errorf("ResetReason = %v", err)
+	}
+	if v := storeVersion(t, s); v != SchemaVersion {
+		t.Errorf("version = %d, want %d", v, SchemaVersion)
+	}
+}
+
+func TestMigrateKeepsOldIndex(t *testing.T) {
+	tests := []struct {
+		name string
+		fill func(tx *bolt.Tx) error
+	}{
+		{"v0", func(tx *bolt.Tx) error {
+			return putLegacyMetas(tx, legacyMetas()...)
+		}},
+		{"v3", func(tx *bolt.Tx) error {
+			if err := putLegacyMetas(tx, legacyMetas()...); err != nil {
+				return err
+			}
+			if err := tx.DeleteBucket([]byte("idx")); err != nil {
+				return err
+			}
+			for _, name := range [][]byte{bFTDoc, bFTText, bFTPosting, bRelated} {
+				if _, err := tx.CreateBucket(name); err != nil {
+					return err
+				}
+			}
+			return setSchemaVersion(tx, 3)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openSeeded(t, seedDB(t, tt.fill))
+			if err := s.ResetReason(); err != nil {
+				t.Fatalf("ResetReason = %v, want nil", err)
+			}
+			if v := storeVersion(t, s); v != SchemaVersion {
+				t.Errorf("version = %d, want %d", v, SchemaVersion)
+			}
+			_ = s.db.View(func(tx *bolt.Tx) error {
+				if tx.Bucket([]byte("idx")) != nil {
+					t.Errorf("legacy idx bucket not dropped")
+				}
+				return nil
+			})
+			list, err := s.List(ListOptions{All: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertStrings(t, "list", slugsOf(list), []string{"b", "a"})
+			bl, err := s.Backlinks("b", ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertStrings(t, "backlinks", slugsOf(bl), []string{"a"})
+		})
+	}
+}
+
+func TestMigrateResetsBrokenIndex(t *testing.T) {
+	tests := []struct {
+		name string
+		fill func(tx *bolt.Tx) error
+	}{
+		{"undecodable meta", func(tx *bolt.Tx) error {
+			if err := putLegacyMetas(tx, legacyMetas()...); err != nil {
+				return err
+			}
+			return tx.Bucket(bMeta).Put([]byte("broken"), []byte("{not json"))
+		}},
+		{"dangling time key", func(tx *bolt.Tx) error {
+			if err := putLegacyMetas(tx, legacyMetas()...); err != nil {
+				return err
+			}
+			return tx.Bucket(bIdxUpdated).Put(makeStickyTimeSlugKey(0, 1, "gone"), []byte{1})
+		}},
+		{"bad version", func(tx *bolt.Tx) error {
+			b, err := tx.CreateBucket(bSchema)
+			if err != nil {
+				return err
+			}
+			return b.Put(schemaKey, []byte("x"))
+		}},
+		{"newer version", func(tx *bolt.Tx) error {
+			if err := putLegacyMetas(tx, legacyMetas()...); err != nil {
+				return err
+			}
+			return setSchemaVersion(tx, SchemaVersion+1)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openSeeded(t, seedDB(t, tt.fill))
+			if err := s.ResetReason(); !errors.Is(err, ErrSchema) {
+				t.Fatalf("ResetReason = %v, want ErrSchema", err)
+			}
+			if v := storeVersion(t, s); v != SchemaVersion {
+				t.Errorf("version = %d, want %d", v, SchemaVersion)
+			}
+			if list, err := s.List(ListOptions{All: true}); err != nil || len(list) != 0 {
+				t.Errorf("List after reset = %q, %v; want empty", slugsOf(list), err)
+			}
+		})
+	}
+}
+
+// 比当前代码新的索引不能被就地改动：migrate 报错且不写入任何东西
+func TestMigrateRejectsNewerVersion(t *testing.T) {
+	path := seedDB(t, func(tx *bolt.Tx) error {
+		return setSchemaVersion(tx, SchemaVersion+1)
+	})
+	db, err := openDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := migrate(db); !errors.Is(err, ErrSchema) {
+		t.Fatalf("migrate = %v, want ErrSchema", err)
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		v, err := schemaVersion(tx)
+		if err == nil && v != SchemaVersion+1 {
+			t.Errorf("version = %d, want it left at %d", v, SchemaVersion+1)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
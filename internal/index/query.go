@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	bolt "go.etcd.io/bbolt"
+	"math"
 	"mygo/internal/domain/config"
 	"mygo/internal/domain/content"
 	"strings"
@@ -12,10 +13,12 @@ import (
 var ErrNotFound = errors.New("not found")
 
 type ListOptions struct {
-	Sort         config.SortMode
-	Page         int
-	Size         int
-	IncludeDraft bool
+	Sort          config.SortMode
+	Page          int
+	Size          int
+	IncludeDraft  bool
+	IncludeFuture bool // 是否包含定时发布（日期在未来）的文章
+	All           bool // 忽略 Page / Size 返回全部，供 build 等需要全量数据的地方使用
 }
 
 func (s *Store) GetMeta(slug string) (content.ArticleMeta, error) {
@@ -85,21 +88,90 @@ func (s *Store) GetByShortID(shortID string) (string, error) {
 	return slug, err
 }
 
-func normalizePaging(page, size int) (int, int) {
+// normalizePaging 修正非法的页码和每页条数，并把每页条数限制在 config.MaxPageSize 以内；
+// 需要全量数据时用 opt.All
+func normalizePaging(opt ListOptions) (int, int) {
+	if opt.All {
+		return 1, math.MaxInt32
+	}
+	page, size := opt.Page, opt.Size
 	if page <= 0 {
 		page = 1
 	}
 	if size <= 0 {
 		size = 10
 	}
-	if size > 100 {
-		size = 100
+	if size > config.MaxPageSize {
+		size = config.MaxPageSize
 	}
 	return page, size
 }
 
+// visible 判断文章在列表中是否可见
+func visible(m content.ArticleMeta, opt ListOptions) bool {
+	if m.Hidden || m.Expired {
+		return false
+	}
+	if m.Draft && !opt.IncludeDraft {
+		return false
+	}
+	if m.Scheduled && !opt.IncludeFuture {
+		return false
+	}
+	return true
+}
+
+// countVisible 统计某个索引 bucket 中可见文章的数量
+func countVisible(b, metaB *bolt.Bucket, slugOf func([]byte) string, opt ListOptions) int {
+	n := 0
+	cur := b.Cursor()
+	for k, _ := cur.First(); k != nil; k, _ = cur.Next() {
+		v := metaB.Get([]byte(slugOf(k)))
+		if v == nil {
+			continue
+		}
+		var m content.ArticleMeta
+		if err := json.Unmarshal(v, &m); err != nil {
+			continue
+		}
+		if visible(m, opt) {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Store) CountByTag(tag string, opt ListOptions) (int, error) {
+	return s.countInSub(bIdxTag, strings.TrimSpace(strings.ToLower(tag)), slugFromStickyTimeSlugKey, opt)
+}
+
+func (s *Store) CountByCategory(cat string, opt ListOptions) (int, error) {
+	return s.countInSub(bIdxCat, strings.TrimSpace(cat), slugFromStickyTimeSlugKey, opt)
+}
+
+func (s *Store) countInSub(parentName []byte, name string, slugOf func([]byte) string, opt ListOptions) (int, error) {
+	if name == "" {
+		return 0, nil
+	}
+	n := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		parent := tx.Bucket(parentName)
+		metaB := tx.Bucket(bMeta)
+		if parent == nil || metaB == nil {
+			return nil
+		}
+		sb := parent.Bucket([]byte(name))
+		if sb == nil {
+			return nil
+		}
+		n = countVisible(sb, metaB, slugOf, opt)
+		return nil
+	})
+	return n, err
+}
+
 func (s *Store) List(opt ListOptions) ([]content.ArticleMeta, error) {
-	opt.Page, opt.Size = normalizePaging(opt.Page, opt.Size)
+	opt.Page, opt.Size = normalizePaging(opt)
 
 	var idxBucketName []byte
 	switch opt.Sort {
@@ -133,10 +205,7 @@ func (s *Store) List(opt ListOptions) ([]content.ArticleMeta, error) {
 			if err := json.Unmarshal(v, &m); err != nil {
 				continue
 			}
-			if m.Hidden {
-				continue
-			}
-			if m.Draft && !opt.IncludeDraft {
+			if !visible(m, opt) {
 				continue
 			}
 			if skip > 0 {
@@ -158,7 +227,7 @@ func (s *Store) ListByTag(tag string, opt ListOptions) ([]content.ArticleMeta, e
 	if tag == "" {
 		return nil, nil
 	}
-	opt.Page, opt.Size = normalizePaging(opt.Page, opt.Size)
+	opt.Page, opt.Size = normalizePaging(opt)
 
 	var out []content.ArticleMeta
 	err := s.db.View(func(tx *bolt.Tx) error {
@@ -184,10 +253,7 @@ func (s *Store) ListByTag(tag string, opt ListOptions) ([]content.ArticleMeta, e
 			if err := json.Unmarshal(v, &m); err != nil {
 				continue
 			}
-			if m.Hidden {
-				continue
-			}
-			if m.Draft && !opt.IncludeDraft {
+			if !visible(m, opt) {
 				continue
 			}
 			if skip > 0 {
@@ -209,7 +275,7 @@ func (s *Store) ListByCategory(cat string, opt ListOptions) ([]content.ArticleMe
 	if cat == "" {
 		return nil, nil
 	}
-	opt.Page, opt.Size = normalizePaging(opt.Page, opt.Size)
+	opt.Page, opt.Size = normalizePaging(opt)
 
 	var out []content.ArticleMeta
 	err := s.db.View(func(tx *bolt.Tx) error {
@@ -235,10 +301,7 @@ func (s *Store) ListByCategory(cat string, opt ListOptions) ([]content.ArticleMe
 			if err := json.Unmarshal(v, &m); err != nil {
 				continue
 			}
-			if m.Hidden {
-				continue
-			}
-			if m.Draft && !opt.IncludeDraft {
+			if !visible(m, opt) {
 				continue
 			}
 			if skip > 0 {
@@ -260,7 +323,7 @@ func (s *Store) ListSeries(name string, opt ListOptions) ([]content.ArticleMeta,
 	if name == "" {
 		return nil, nil
 	}
-	opt.Page, opt.Size = normalizePaging(opt.Page, opt.Size)
+	opt.Page, opt.Size = normalizePaging(opt)
 
 	var out []content.ArticleMeta
 	err := s.db.View(func(tx *bolt.Tx) error {
@@ -290,10 +353,7 @@ func (s *Store) ListSeries(name string, opt ListOptions) ([]content.ArticleMeta,
 			if err := json.Unmarshal(v, &m); err != nil {
 				continue
 			}
-			if m.Hidden {
-				continue
-			}
-			if m.Draft && !opt.IncludeDraft {
+			if !visible(m, opt) {
 				continue
 			}
 			if skip > 0 {
@@ -371,10 +431,8 @@ func (s *Store) GetSeriesSummary(name string, includeDraft bool) (*SeriesSummary
 			if err := json.Unmarshal(v, &m); err != nil {
 				continue
 			}
-			if m.Hidden {
-				continue
-			}
-			if m.Draft && !includeDraft {
+			// 定时文章是否入索引已由 Rebuild 决定
+			if !visible(m, ListOptions{IncludeDraft: includeDraft, IncludeFuture: true}) {
 				continue
 			}
 
@@ -0,0 +1,339 @@
+package index
+
+import (
+	"encoding/json"
+	bolt "go.etcd.io/bbolt"
+	"math"
+	"mygo/internal/domain/content"
+	"sort"
+	"strings"
+)
+
+// 相关文章：共同标签、同分类、同系列各记一定分数，再加上正文 TF-IDF 向量的余弦相似度。
+// 结果按文章保存在 bRelated 里，Sync / Upsert / Delete 之后只重算受影响的文章
+
+const (
+	relatedTagScore    = 1.0 // 每个共同标签
+	relatedCatScore    = 0.5
+	relatedSeriesScore = 1.5
+	relatedTextScore   = 3.0 // 余弦相似度（0~1）的权重
+)
+
+type relatedItem struct {
+	Slug  string  `json:"slug"`
+	Score float64 `json:"score"`
+}
+
+// relatedList 是一篇文章的相关文章；N 是计算时的条数上限，配置改变后据此重算
+type relatedList struct {
+	N     int           `json:"n"`
+	Items []relatedItem `json:"items"`
+}
+
+// minScore 返回列表里能被挤掉的最低分；列表没满时任何正分都能进入
+func (l relatedList) minScore() float64 {
+	if len(l.Items) < l.N {
+		return 0
+	}
+	return l.Items[len(l.Items)-1].Score
+}
+
+// relatedCorpus 是一次计算所需的全部数据，从当前事务里一次读出
+type relatedCorpus struct {
+	metas  map[string]content.ArticleMeta
+	vecs   map[string]map[string]float64 // 归一化后的 TF-IDF 向量
+	byTerm map[string][]string           // term -> 含有它的文章，过于常见的词不参与
+	byTag  map[string][]string
+	byCat  map[string][]string
+	bySer  map[string][]string
+}
+
+func loadRelatedCorpus(tx *bolt.Tx) (*relatedCorpus, error) {
+	c := &relatedCorpus{
+		metas:  make(map[string]content.ArticleMeta),
+		vecs:   make(map[string]map[string]float64),
+		byTerm: make(map[string][]string),
+		byTag:  make(map[string][]string),
+		byCat:  make(map[string][]string),
+		bySer:  make(map[string][]string),
+	}
+	err := tx.Bucket(bMeta).ForEach(func(k, v []byte) error {
+		var m content.ArticleMeta
+		if err := json.Unmarshal(v, &m); err != nil {
+			return err
+		}
+		slug := string(k)
+		c.metas[slug] = m
+		for _, t := range m.Tags {
+			c.byTag[t] = append(c.byTag[t], slug)
+		}
+		if cat := strings.TrimSpace(m.Category); cat != "" {
+			c.byCat[cat] = append(c.byCat[cat], slug)
+		}
+		if sn := strings.TrimSpace(m.Series.Name); sn != "" {
+			c.bySer[sn] = append(c.bySer[sn], slug)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tf := make(map[string]map[string]float64) // slug -> term -> 加权词频
+	err = tx.Bucket(bFTPosting).ForEach(func(k, v []byte) error {
+		term, slug, ok := strings.Cut(string(k), "\x00")
+		if !ok {
+			return nil
+		}
+		if _, ok := c.metas[slug]; !ok {
+			return nil
+		}
+		f := postingTF(v)
+		if f == 0 {
+			return nil
+		}
+		if tf[slug] == nil {
+			tf[slug] = make(map[string]float64)
+		}
+		tf[slug][term] = f
+		c.byTerm[term] = append(c.byTerm[term], slug)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	n := float64(len(c.metas))
+	maxDF := max(10, len(c.metas)/2)
+	for term, slugs := range c.byTerm {
+		if len(slugs) > maxDF || len(slugs) < 2 {
+			// 太常见的词区分度低，只出现一次的词不会带来相似度
+			delete(c.byTerm, term)
+		}
+	}
+	for slug, terms := range tf {
+		vec := make(map[string]float64, len(terms))
+		norm := 0.0
+		for term, f := range terms {
+			if _, ok := c.byTerm[term]; !ok {
+				continue
+			}
+			w := (1 + math.Log(f)) * math.Log(n/float64(len(c.byTerm[term])))
+			if w <= 0 {
+				continue
+			}
+			vec[term] = w
+			norm += w * w
+		}
+		norm = math.Sqrt(norm)
+		for term := range vec {
+			vec[term] /= norm
+		}
+		c.vecs[slug] = vec
+	}
+	return c, nil
+}
+
+// pair 计算两篇文章的相关度
+func (c *relatedCorpus) pair(a, b string) float64 {
+	ma, mb := c.metas[a], c.metas[b]
+	score := 0.0
+	for _, t := range ma.Tags {
+		for _, u := range mb.Tags {
+			if t == u {
+				score += relatedTagScore
+			}
+		}
+	}
+	if ma.Category != "" && ma.Category == mb.Category {
+		score += relatedCatScore
+	}
+	if ma.Series.Name != "" && ma.Series.Name == mb.Series.Name {
+		score += relatedSeriesScore
+	}
+	va, vb := c.vecs[a], c.vecs[b]
+	if len(vb) < len(va) {
+		va, vb = vb, va
+	}
+	dot := 0.0
+	for term, w := range va {
+		dot += w * vb[term]
+	}
+	return score + relatedTextScore*dot
+}
+
+// compute 算出 slug 的前 n 篇相关文章：先经由标签 / 分类 / 系列 / 共同词找出候选，再逐个打分
+func (c *relatedCorpus) compute(slug string, n int) relatedList {
+	m := c.metas[slug]
+	cand := make(map[string]struct{})
+	add := func(slugs []string) {
+		for _, s := range slugs {
+			cand[s] = struct{}{}
+		}
+	}
+	for _, t := range m.Tags {
+		add(c.byTag[t])
+	}
+	add(c.byCat[strings.TrimSpace(m.Category)])
+	add(c.bySer[strings.TrimSpace(m.Series.Name)])
+	for term := range c.vecs[slug] {
+		add(c.byTerm[term])
+	}
+	delete(cand, slug)
+
+	list := relatedList{N: n}
+	for other := range cand {
+		if s := c.pair(slug, other); s > 0 {
+			list.Items = append(list.Items, relatedItem{Slug: other, Score: s})
+		}
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		a, b := list.Items[i], list.Items[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		da, db := c.metas[a.Slug].Date, c.metas[b.Slug].Date
+		if !da.Equal(db) {
+			return da.After(db)
+		}
+		return a.Slug < b.Slug
+	})
+	if len(list.Items) > n {
+		list.Items = list.Items[:n]
+	}
+	return list
+}
+
+// updateRelated 重算相关文章。full 为 false 时只处理受 changed（写入或删除过的 slug）影响的文章：
+// 本身变了、列表里引用了变动的文章、或与变动文章的相关度足以挤进列表。
+// 未受影响的文章沿用旧结果，全局词频带来的细微分数变化要等下次全量重建才反映出来
+func updateRelated(tx *bolt.Tx, changed map[string]bool, full bool, n int) error {
+	b, err := tx.CreateBucketIfNotExists(bRelated)
+	if err != nil {
+		return err
+	}
+	if !full && len(changed) == 0 {
+		if cur, ok := relatedN(tx); ok && cur == n {
+			return nil
+		}
+	}
+	c, err := loadRelatedCorpus(tx)
+	if err != nil {
+		return err
+	}
+
+	var stale [][]byte
+	err = b.ForEach(func(k, _ []byte) error {
+		if _, ok := c.metas[string(k)]; !ok || n <= 0 {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range stale {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	slugs := make([]string, 0, len(c.metas))
+	for slug := range c.metas {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	for _, slug := range slugs {
+		var old relatedList
+		v := b.Get([]byte(slug))
+		need := full || v == nil || changed[slug]
+		if !need {
+			if err := json.Unmarshal(v, &old); err != nil || old.N != n {
+				need = true
+			}
+		}
+		for _, it := range old.Items {
+			if need {
+				break
+			}
+			need = changed[it.Slug]
+		}
+		if !need {
+			low := old.minScore()
+			for ch := range changed {
+				if _, ok := c.metas[ch]; ok && ch != slug && c.pair(slug, ch) > low {
+					need = true
+					break
+				}
+			}
+		}
+		if !need {
+			continue
+		}
+		lv, err := json.Marshal(c.compute(slug, n))
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(slug), lv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relatedN 返回已保存的相关文章条数上限；没有任何结果时 ok 为 false（没有文章时视为已是最新）
+func relatedN(tx *bolt.Tx) (n int, ok bool) {
+	b := tx.Bucket(bRelated)
+	if b == nil {
+		return 0, false
+	}
+	k, v := b.Cursor().First()
+	if k == nil {
+		if mk, _ := tx.Bucket(bMeta).Cursor().First(); mk == nil {
+			return 0, true
+		}
+		return 0, false
+	}
+	var list relatedList
+	if err := json.Unmarshal(v, &list); err != nil {
+		return 0, false
+	}
+	return list.N, true
+}
+
+// Related 返回 slug 的相关文章，按相关度排列，并按 opt 过滤草稿 / 定时文章
+func (s *Store) Related(slug string, opt ListOptions) ([]content.ArticleMeta, error) {
+	var out []content.ArticleMeta
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b, metaB := tx.Bucket(bRelated), tx.Bucket(bMeta)
+		if b == nil || metaB == nil {
+			return nil
+		}
+		v := b.Get([]byte(slug))
+		if v == nil {
+			return nil
+		}
+		var list relatedList
+		if err := json.Unmarshal(v, &list); err != nil {
+			return err
+		}
+		for _, it := range list.Items {
+			mv := metaB.Get([]byte(it.Slug))
+			if mv == nil {
+				continue
+			}
+			var m content.ArticleMeta
+			if err := json.Unmarshal(mv, &m); err != nil {
+				return err
+			}
+			if visible(m, opt) {
+				out = append(out, m)
+			}
+		}
+		return nil
+	})
+	return out, err
+}
@@ -0,0 +1,105 @@
+package index
+
+import (
+	"mygo/internal/domain/content"
+	"testing"
+)
+
+// relatedFixture 里各篇与 base 的相关度只来自标签 / 分类 / 系列（标题各不相同，不产生正文相似度）
+func relatedFixture() []content.Article {
+	tagged := func(slug string, day int, cat, series string, tags ...string) content.Article {
+		return testArticle(slug, day, func(a *content.Article) {
+			a.Meta.Tags = tags
+			a.Meta.Category = cat
+			a.Meta.Series = content.Series{Name: series}
+		})
+	}
+	draft := tagged("draft", 10, "dev", "s", "a", "b")
+	draft.Meta.Draft = true
+	return []content.Article{
+		tagged("base", 1, "dev", "s", "a", "b"),
+		tagged("s1", 2, "", "s", "a", "b"),   // 2 + 1.5
+		tagged("s2", 3, "dev", "", "a", "b"), // 2 + 0.5
+		tagged("s3", 4, "dev", "", "a"),      // 1 + 0.5
+		tagged("s4", 5, "", "", "a"),         // 1
+		tagged("tie", 6, "", "", "b"),        // 1，与 s4 同分，发布较晚的在前
+		tagged("s5", 7, "dev", ""),           // 0.5
+		tagged("unrelated", 8, "", "", "z"),
+		draft,
+	}
+}
+
+func relatedSlugs(t *testing.T, s *Store, slug string, opt ListOptions) []string {
+	t.Helper()
+	got, err := s.Related(slug, opt)
+	if err != nil {
+		t.Fatalf("Related(%s): %v", slug, err)
+	}
+	return slugsOf(got)
+}
+
+func TestRelated(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  RebuildOptions
+		list ListOptions
+		want []string
+	}{
+		{"ordered", RebuildOptions{Related: 10}, ListOptions{},
+			[]string{"s1", "s2", "s3", "tie", "s4", "s5"}},
+		{"limit", RebuildOptions{Related: 3}, ListOptions{},
+			[]string{"s1", "s2", "s3"}},
+		// 草稿只在预览时写入索引，同样参与排名，列表默认不显示
+		{"draft indexed but filtered", RebuildOptions{Related: 3, IncludeDraft: true}, ListOptions{},
+			[]string{"s1", "s2"}},
+		{"draft shown", RebuildOptions{Related: 3, IncludeDraft: true}, ListOptions{IncludeDraft: true},
+			[]string{"draft", "s1", "s2"}},
+		{"disabled", RebuildOptions{}, ListOptions{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openTestStore(t)
+			if err := s.Rebuild(relatedFixture(), tt.opt); err != nil {
+				t.Fatal(err)
+			}
+			assertStrings(t, "related", relatedSlugs(t, s, "base", tt.list), tt.want)
+		})
+	}
+}
+
+func TestRelatedExcludesSelf(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.Rebuild(relatedFixture(), RebuildOptions{Related: 10}); err != nil {
+		t.Fatal(err)
+	}
+	for _, slug := range []string{"base", "s1", "s4"} {
+		for _, got := range relatedSlugs(t, s, slug, ListOptions{IncludeDraft: true}) {
+			if got == slug {
+				t.Errorf("Related(%s) contains %s", slug, got)
+			}
+		}
+	}
+}
+
+func TestRelatedIncremental(t *testing.T) {
+	s := openTestStore(t)
+	opt := RebuildOptions{Related: 3}
+	if err := s.Rebuild(relatedFixture(), opt); err != nil {
+		t.Fatal(err)
+	}
+
+	top := testArticle("top", 11, func(a *content.Article) {
+		a.Meta.Tags = []string{"a", "b"}
+		a.Meta.Category = "dev"
+		a.Meta.Series = content.Series{Name: "s"}
+	})
+	if err := s.Upsert(&top, opt); err != nil {
+		t.Fatal(err)
+	}
+	assertStrings(t, "after upsert", relatedSlugs(t, s, "base", ListOptions{}), []string{"top", "s1", "s2"})
+
+	if err := s.Delete("top"); err != nil {
+		t.Fatal(err)
+	}
+	assertStrings(t, "after delete", relatedSlugs(t, s, "base", ListOptions{}), []string{"s1", "s2", "s3"})
+}
@@ -4,11 +4,28 @@ var (
 	bMeta      = []byte("meta")       // slug -> metaBytes
 	bAlias     = []byte("alias")      // old -> newSlug
 	bShort     = []byte("short")      // shortID -> slug
-	bIdx       = []byte("idx")        // parent bucket for indices
 	bIdxTag    = []byte("idx_tag")    // tag -> sub-bucket
 	bIdxCat    = []byte("idx_cat")    // cat -> sub-bucket
 	bIdxSeries = []byte("idx_series") // seriesName -> sub-bucket
 
 	bIdxUpdated = []byte("idx_updated")
 	bIdxCreated = []byte("idx_created")
+
+	bFingerprint = []byte("fingerprint") // outPath -> build.Fingerprint，Rebuild 不会清空
+	bShortIDs    = []byte("short_ids")   // "path:<源文件>" / "slug:<slug>" -> 自动短 ID，Rebuild 不会清空
+	bSchema      = []byte("schema")      // "version" -> 索引布局版本（十进制），见 migrate.go
+
+	// 全文索引，见 fulltext.go
+	bFTDoc     = []byte("ft_doc")     // slug -> ftDoc
+	bFTText    = []byte("ft_text")    // slug -> 正文纯文本，用于截取片段、判断正文是否变化
+	bFTPosting = []byte("ft_posting") // term 0x00 slug -> 标题词频 uvarint + 正文词频 uvarint
+
+	bRelated   = []byte("related")   // slug -> relatedList，见 related.go
+	bBacklinks = []byte("backlinks") // 被链接的 slug 0x00 来源 slug -> 1，见 backlinks.go
 )
+
+// articleBuckets 是由文章派生的 bucket，Rebuild 会整体重建，Upsert / Delete 逐项维护
+var articleBuckets = [][]byte{
+	bMeta, bAlias, bShort, bIdxUpdated, bIdxCreated, bIdxTag, bIdxCat, bIdxSeries,
+	bFTDoc, bFTText, bFTPosting, bRelated, bBacklinks,
+}
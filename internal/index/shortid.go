@@ -0,0 +1,149 @@
+package index
+
+import (
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"mygo/internal/domain/content"
+	domainerr "mygo/internal/domain/errors"
+	"sort"
+	"strings"
+)
+
+// shortIDMinLen 自动短 ID 的最小长度；冲突时逐位加长
+const shortIDMinLen = 4
+
+// shortIDEncoding 小写 base32，不带填充，URL 里可以直接使用
+var shortIDEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// assignShortIDs 检查手写短 ID 是否重复，并为没有短 ID 的文章分配自动短 ID（直接回填到 articles）。
+// 自动分配的结果按源文件路径和 slug 记在 bShortIDs 里，文章改名或移动后仍沿用原来的 ID；
+// 改过 slug 的文章还能通过 aliases 里的旧 slug 找回。
+func assignShortIDs(tx *bolt.Tx, articles []content.Article) error {
+	b, err := tx.CreateBucketIfNotExists(bShortIDs)
+	if err != nil {
+		return err
+	}
+
+	// 按源文件路径排序，保证分配结果与 ingest 的并发顺序无关
+	order := make([]int, len(articles))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return articles[order[i]].Body.SourcePath < articles[order[j]].Body.SourcePath
+	})
+
+	taken := make(map[string]string) // id -> 占用它的源文件
+	for _, i := range order {
+		a := &articles[i]
+		id := strings.TrimSpace(a.Meta.ShortID)
+		if id == "" {
+			continue
+		}
+		if other, ok := taken[id]; ok {
+			return fmt.Errorf("%w: short id %q is used by both %s and %s",
+				domainerr.ErrInvalid, id, other, a.Body.SourcePath)
+		}
+		taken[id] = a.Body.SourcePath
+	}
+
+	for _, i := range order {
+		a := &articles[i]
+		if strings.TrimSpace(a.Meta.ShortID) != "" || strings.TrimSpace(a.Meta.Slug) == "" {
+			continue
+		}
+		if err := autoShortID(b, a, taken); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignShortID 是 assignShortIDs 的单篇版本，供 Upsert 使用：已占用的 ID 以 bShort 为准
+func assignShortID(tx *bolt.Tx, a *content.Article) error {
+	b, err := tx.CreateBucketIfNotExists(bShortIDs)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(a.Meta.Slug) == "" {
+		return nil
+	}
+
+	taken := make(map[string]string) // id -> 占用它的 slug
+	err = tx.Bucket(bShort).ForEach(func(k, v []byte) error {
+		if string(v) != a.Meta.Slug {
+			taken[string(k)] = string(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if id := strings.TrimSpace(a.Meta.ShortID); id != "" {
+		if other, ok := taken[id]; ok {
+			return fmt.Errorf("%w: short id %q of %s is already used by %s",
+				domainerr.ErrInvalid, id, a.Body.SourcePath, other)
+		}
+		return nil
+	}
+	return autoShortID(b, a, taken)
+}
+
+// autoShortID 沿用之前分配过的 ID，没有或已被占用时重新生成，并记录到 bShortIDs
+func autoShortID(b *bolt.Bucket, a *content.Article, taken map[string]string) error {
+	id := lookupShortID(b, a)
+	if _, ok := taken[id]; id == "" || ok {
+		id = newShortID(a.Body.SourcePath, taken)
+	}
+	taken[id] = a.Body.SourcePath
+	a.Meta.ShortID = id
+
+	if err := b.Put(shortIDKey("path", a.Body.SourcePath), []byte(id)); err != nil {
+		return err
+	}
+	return b.Put(shortIDKey("slug", a.Meta.Slug), []byte(id))
+}
+
+// lookupShortID 依次按源文件路径、slug、旧 slug 查之前分配过的 ID
+func lookupShortID(b *bolt.Bucket, a *content.Article) string {
+	keys := [][]byte{
+		shortIDKey("path", a.Body.SourcePath),
+		shortIDKey("slug", a.Meta.Slug),
+	}
+	for _, old := range a.Meta.Aliases {
+		if !strings.Contains(old, "/") {
+			keys = append(keys, shortIDKey("slug", old))
+		}
+	}
+	for _, k := range keys {
+		if v := b.Get(k); v != nil {
+			return string(v)
+		}
+	}
+	return ""
+}
+
+// newShortID 由源文件路径的 hash 生成 ID，与已占用的冲突时加长
+func newShortID(sourcePath string, taken map[string]string) string {
+	sum := sha1.Sum([]byte(sourcePath))
+	enc := shortIDEncoding.EncodeToString(sum[:])
+	for n := shortIDMinLen; n <= len(enc); n++ {
+		if _, ok := taken[enc[:n]]; !ok {
+			return enc[:n]
+		}
+	}
+	// 32 字符的 sha1 都冲突只可能是同一路径，这里加后缀兜底
+	for i := 2; ; i++ {
+		id := fmt.Sprintf("%s%d", enc, i)
+		if _, ok := taken[id]; !ok {
+			return id
+		}
+	}
+}
+
+func shortIDKey(kind, v string) []byte {
+	return []byte(kind + ":" + v)
+}
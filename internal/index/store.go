@@ -2,6 +2,7 @@ package index
 
 import (
 	"errors"
+	"fmt"
 	bolt "go.etcd.io/bbolt"
 	"os"
 	"path/filepath"
@@ -9,7 +10,8 @@ import (
 )
 
 type Store struct {
-	db *bolt.DB
+	db    *bolt.DB
+	reset error // Open 时索引无法迁移而被重置的原因
 }
 
 type OpenOptions struct {
@@ -23,13 +25,43 @@ func Open(opt OpenOptions) (*Store, error) {
 	if err := os.MkdirAll(filepath.Dir(opt.Path), 0o755); err != nil {
 		return nil, err
 	}
-	db, err := bolt.Open(opt.Path, 0o600, &bolt.Options{
-		Timeout: 1 * time.Second,
-	})
+	db, err := openDB(opt.Path)
+	if err != nil {
+		return nil, err
+	}
+	migErr := migrate(db)
+	if migErr == nil {
+		return &Store{db: db}, nil
+	}
+
+	// 索引完全由源文件派生：无法升级时丢弃旧文件，由调用方从源文件重建
+	_ = db.Close()
+	if err := os.Remove(opt.Path); err != nil {
+		return nil, fmt.Errorf("index: %w; remove %s: %v", migErr, opt.Path, err)
+	}
+	db, err = openDB(opt.Path)
 	if err != nil {
 		return nil, err
 	}
-	return &Store{db: db}, nil
+	if err := migrate(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Store{
+		db:    db,
+		reset: fmt.Errorf("%w; the old file was discarded and the index is rebuilt from source", migErr),
+	}, nil
+}
+
+func openDB(path string) (*bolt.DB, error) {
+	return bolt.Open(path, 0o600, &bolt.Options{
+		Timeout: 1 * time.Second,
+	})
+}
+
+// ResetReason 返回 Open 时丢弃旧索引的原因，正常打开时为 nil
+func (s *Store) ResetReason() error {
+	return s.reset
 }
 
 func (s *Store) Close() error {
@@ -0,0 +1,139 @@
+package index
+
+import (
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"mygo/internal/domain/content"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(OpenOptions{Path: filepath.Join(t.TempDir(), "index.db")})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+// testArticle 造一篇文章，day 决定发布日期（2024-01-<day>），edit 可以再改其它字段
+func testArticle(slug string, day int, edit func(a *content.Article)) content.Article {
+	d := time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC)
+	a := content.Article{
+		Meta: content.ArticleMeta{Title: slug, Slug: slug, Date: d, Updated: d},
+		Body: content.BodyRef{SourcePath: "source/" + slug + ".md"},
+	}
+	if edit != nil {
+		edit(&a)
+	}
+	return a
+}
+
+// row 是 bucket 里的一条记录；sub 为所在子 bucket 名（标签 / 分类 / 系列），
+// 空的子 bucket 记为一条 key、val 都为空的记录
+type row struct {
+	bucket, sub, key, val string
+}
+
+func (r row) String() string {
+	return fmt.Sprintf("%s/%q/%q=%q", r.bucket, r.sub, r.key, r.val)
+}
+
+// dumpBuckets 列出 articleBuckets 里的全部记录
+func dumpBuckets(t *testing.T, s *Store) []row {
+	t.Helper()
+	var out []row
+	err := s.db.View(func(tx *bolt.Tx) error {
+		for _, name := range articleBuckets {
+			b := tx.Bucket(name)
+			if b == nil {
+				continue
+			}
+			err := b.ForEach(func(k, v []byte) error {
+				if v != nil {
+					out = append(out, row{bucket: string(name), key: string(k), val: string(v)})
+					return nil
+				}
+				sb := b.Bucket(k)
+				if first, _ := sb.Cursor().First(); first == nil {
+					out = append(out, row{bucket: string(name), sub: string(k)})
+				}
+				return sb.ForEach(func(sk, sv []byte) error {
+					out = append(out, row{bucket: string(name), sub: string(k), key: string(sk), val: string(sv)})
+					return nil
+				})
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("dump: %v", err)
+	}
+	return out
+}
+
+// refersTo 找出仍然引用 slug 的记录：以 slug 为键（旧地址除外）或值、复合键以 slug 结尾，
+// 或者相关文章列表里还有它。反向链接里以 slug 为被链接方的记录按设计保留，不算在内
+func refersTo(rows []row, slug string) []row {
+	var out []row
+	for _, r := range rows {
+		if (r.key == slug && r.bucket != string(bAlias)) || r.val == slug ||
+			strings.HasSuffix(r.key, "\x00"+slug) ||
+			(r.bucket == string(bRelated) && strings.Contains(r.val, `"`+slug+`"`)) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// bucketRows 返回某个 bucket 里的记录，写成 "键=值"（子 bucket 中写成 "子 bucket 名:键=值"）并排序
+func bucketRows(rows []row, bucket []byte) []string {
+	var out []string
+	for _, r := range rows {
+		if r.bucket != string(bucket) {
+			continue
+		}
+		s := r.key + "=" + r.val
+		if r.sub != "" {
+			s = r.sub + ":" + s
+		}
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// subBucketNames 返回 parent 下的子 bucket 名
+func subBucketNames(t *testing.T, s *Store, parent []byte) []string {
+	t.Helper()
+	var names []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(parent).ForEach(func(k, v []byte) error {
+			if v == nil {
+				names = append(names, string(k))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("list %s: %v", parent, err)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func slugsOf(metas []content.ArticleMeta) []string {
+	out := make([]string, len(metas))
+	for i, m := range metas {
+		out[i] = m.Slug
+	}
+	return out
+}
@@ -8,109 +8,126 @@ import (
 )
 
 type RebuildOptions struct {
-	IncludeDraft bool
+	IncludeDraft  bool
+	IncludeFuture bool // 定时发布的文章也写入索引；已过期的文章总是跳过
+	Related       int  // 每篇文章保存的相关文章数，0 表示不计算
 }
 
+// Rebuild 用 articles 重建索引。没有短 ID 的文章会被分配自动短 ID 并回填到 articles 里；
+// 手写短 ID 重复时返回错误
 func (s *Store) Rebuild(articles []content.Article, opt RebuildOptions) error {
 	return s.db.Update(func(tx *bolt.Tx) error {
-		_ = tx.DeleteBucket(bMeta)
-		_ = tx.DeleteBucket(bAlias)
-		_ = tx.DeleteBucket(bShort)
-		_ = tx.DeleteBucket(bIdx)
-		_ = tx.DeleteBucket(bIdxTag)
-		_ = tx.DeleteBucket(bIdxCat)
-		_ = tx.DeleteBucket(bIdxSeries)
-		_ = tx.DeleteBucket(bIdxUpdated)
-		_ = tx.DeleteBucket(bIdxCreated)
-
-		metaB, _ := tx.CreateBucket(bMeta)
-		aliasB, _ := tx.CreateBucket(bAlias)
-		shortB, _ := tx.CreateBucket(bShort)
-
-		idxUpdatedB, _ := tx.CreateBucket(bIdxUpdated)
-		idxCreatedB, _ := tx.CreateBucket(bIdxCreated)
-
-		idxTagB, _ := tx.CreateBucket(bIdxTag)
-		idxCatB, _ := tx.CreateBucket(bIdxCat)
-		idxSeriesB, _ := tx.CreateBucket(bIdxSeries)
+		if err := assignShortIDs(tx, articles); err != nil {
+			return err
+		}
 
-		for _, a := range articles {
-			m := a.Meta
-			if m.Draft && !opt.IncludeDraft {
-				continue
+		for _, name := range articleBuckets {
+			_ = tx.DeleteBucket(name)
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
 			}
-			if strings.TrimSpace(m.Slug) == "" {
+		}
+
+		for _, a := range articles {
+			if !indexable(a.Meta, opt) {
 				continue
 			}
-			mb, err := json.Marshal(m)
-			if err != nil {
-				return err
-			}
-			if err := metaB.Put([]byte(m.Slug), mb); err != nil {
+			if err := putArticle(tx, a); err != nil {
 				return err
 			}
+		}
+		return updateRelated(tx, nil, true, opt.Related)
+	})
+}
 
-			uKey := makeStickyTimeSlugKey(m.Sticky, m.Updated.UnixNano(), m.Slug)
-			if err := idxUpdatedB.Put(uKey, []byte{1}); err != nil {
-				return err
-			}
+// indexable 判断文章是否应该写入索引
+func indexable(m content.ArticleMeta, opt RebuildOptions) bool {
+	if m.Draft && !opt.IncludeDraft {
+		return false
+	}
+	if m.Expired || (m.Scheduled && !opt.IncludeFuture) {
+		return false
+	}
+	return strings.TrimSpace(m.Slug) != ""
+}
 
-			cKey := makeStickyTimeSlugKey(m.Sticky, m.Date.UnixNano(), m.Slug)
-			if err := idxCreatedB.Put(cKey, []byte{1}); err != nil {
-				return err
-			}
+// putArticle 写入一篇文章的 meta、各个二级索引、反向链接和全文索引；调用方保证 slug 下没有旧数据
+func putArticle(tx *bolt.Tx, a content.Article) error {
+	m := a.Meta
+	metaB, aliasB, shortB := tx.Bucket(bMeta), tx.Bucket(bAlias), tx.Bucket(bShort)
+	idxUpdatedB, idxCreatedB := tx.Bucket(bIdxUpdated), tx.Bucket(bIdxCreated)
+	idxTagB, idxCatB, idxSeriesB := tx.Bucket(bIdxTag), tx.Bucket(bIdxCat), tx.Bucket(bIdxSeries)
 
-			for _, tag := range m.Tags {
-				if tag == "" {
-					continue
-				}
-				sb, err := idxTagB.CreateBucketIfNotExists([]byte(tag))
-				if err != nil {
-					return err
-				}
-				if err := sb.Put(uKey, []byte{1}); err != nil {
-					return err
-				}
+	mb, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := metaB.Put([]byte(m.Slug), mb); err != nil {
+		return err
+	}
 
-			}
+	uKey := makeStickyTimeSlugKey(m.Sticky, m.Updated.UnixNano(), m.Slug)
+	if err := idxUpdatedB.Put(uKey, []byte{1}); err != nil {
+		return err
+	}
 
-			if cat := strings.TrimSpace(m.Category); cat != "" {
-				sb, err := idxCatB.CreateBucketIfNotExists([]byte(cat))
-				if err != nil {
-					return err
-				}
-				if err := sb.Put(uKey, []byte{1}); err != nil {
-					return err
-				}
-			}
+	cKey := makeStickyTimeSlugKey(m.Sticky, m.Date.UnixNano(), m.Slug)
+	if err := idxCreatedB.Put(cKey, []byte{1}); err != nil {
+		return err
+	}
 
-			if sn := strings.TrimSpace(m.Series.Name); sn != "" {
-				sb, err := idxSeriesB.CreateBucketIfNotExists([]byte(sn))
-				if err != nil {
-					return err
-				}
-				sKey := makeSeriesKey(m.Series.Order, m.Updated.UnixNano(), m.Slug)
-				if err := sb.Put(sKey, []byte{1}); err != nil {
-					return err
-				}
-			}
-			for _, old := range m.Aliases {
-				old = strings.TrimSpace(old)
-				if old == "" {
-					continue
-				}
-				if err := aliasB.Put([]byte(old), []byte(m.Slug)); err != nil {
-					return err
-				}
-			}
-			if sid := strings.TrimSpace(m.ShortID); sid != "" {
-				if err := shortB.Put([]byte(sid), []byte(m.Slug)); err != nil {
-					return err
-				}
-			}
+	for _, tag := range m.Tags {
+		if tag == "" {
+			continue
 		}
-		return nil
-	})
+		sb, err := idxTagB.CreateBucketIfNotExists([]byte(tag))
+		if err != nil {
+			return err
+		}
+		if err := sb.Put(uKey, []byte{1}); err != nil {
+			return err
+		}
+
+	}
+
+	if cat := strings.TrimSpace(m.Category); cat != "" {
+		sb, err := idxCatB.CreateBucketIfNotExists([]byte(cat))
+		if err != nil {
+			return err
+		}
+		if err := sb.Put(uKey, []byte{1}); err != nil {
+			return err
+		}
+	}
+
+	if sn := strings.TrimSpace(m.Series.Name); sn != "" {
+		sb, err := idxSeriesB.CreateBucketIfNotExists([]byte(sn))
+		if err != nil {
+			return err
+		}
+		sKey := makeSeriesKey(m.Series.Order, m.Updated.UnixNano(), m.Slug)
+		if err := sb.Put(sKey, []byte{1}); err != nil {
+			return err
+		}
+	}
+	for _, old := range m.Aliases {
+		old = strings.TrimSpace(old)
+		if old == "" {
+			continue
+		}
+		if err := aliasB.Put([]byte(old), []byte(m.Slug)); err != nil {
+			return err
+		}
+	}
+	if sid := strings.TrimSpace(m.ShortID); sid != "" {
+		if err := shortB.Put([]byte(sid), []byte(m.Slug)); err != nil {
+			return err
+		}
+	}
+	if err := putBacklinks(tx, m); err != nil {
+		return err
+	}
+	return putFullText(tx, a)
 }
 
 func makeSeriesKey(order int, updatedUnixNano int64, slug string) []byte {
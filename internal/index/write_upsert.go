@@ -0,0 +1,225 @@
+package index
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	bolt "go.etcd.io/bbolt"
+	"mygo/internal/domain/content"
+	"sort"
+	"strings"
+)
+
+// Upsert 写入或替换一篇文章，只改动与它相关的索引项。没有短 ID 时分配自动短 ID 并回填到 a；
+// 按 opt 不该出现在索引里的文章（草稿、定时、过期）会被移除。
+// slug 改变时旧 slug 不会自动清理，需要调用方 Delete
+func (s *Store) Upsert(a *content.Article, opt RebuildOptions) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := ensureArticleBuckets(tx); err != nil {
+			return err
+		}
+		if err := assignShortID(tx, a); err != nil {
+			return err
+		}
+		if err := upsertArticle(tx, *a, opt); err != nil {
+			return err
+		}
+		return updateRelated(tx, map[string]bool{a.Meta.Slug: true}, false, opt.Related)
+	})
+}
+
+// Delete 移除一篇文章及其全部索引项；slug 不存在时什么也不做
+func (s *Store) Delete(slug string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := ensureArticleBuckets(tx); err != nil {
+			return err
+		}
+		if err := deleteArticle(tx, slug); err != nil {
+			return err
+		}
+		// 沿用已保存结果的条数，只清理引用了这篇文章的列表
+		if n, ok := relatedN(tx); ok && n > 0 {
+			return updateRelated(tx, map[string]bool{slug: true}, false, n)
+		}
+		return nil
+	})
+}
+
+// SyncStats 统计一次 Sync 实际改动的文章数
+type SyncStats struct {
+	Upserted  int
+	Deleted   int
+	Unchanged int
+}
+
+// Sync 让索引与 articles 保持一致：meta 没变的文章不动，变了的逐篇替换，索引里多出来的删除。
+// 短 ID 的检查与分配和 Rebuild 相同
+func (s *Store) Sync(articles []content.Article, opt RebuildOptions) (SyncStats, error) {
+	var stats SyncStats
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := ensureArticleBuckets(tx); err != nil {
+			return err
+		}
+		if err := assignShortIDs(tx, articles); err != nil {
+			return err
+		}
+
+		want := make(map[string]content.Article, len(articles))
+		for _, a := range articles {
+			if indexable(a.Meta, opt) {
+				want[a.Meta.Slug] = a
+			}
+		}
+
+		// 先删除：被删文章的旧地址 / 短 ID 可能已经转给了别的文章
+		metaB := tx.Bucket(bMeta)
+		var stale []string
+		err := metaB.ForEach(func(k, _ []byte) error {
+			if _, ok := want[string(k)]; !ok {
+				stale = append(stale, string(k))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		changed := make(map[string]bool)
+		for _, slug := range stale {
+			if err := deleteArticle(tx, slug); err != nil {
+				return err
+			}
+			changed[slug] = true
+			stats.Deleted++
+		}
+
+		slugs := make([]string, 0, len(want))
+		for slug := range want {
+			slugs = append(slugs, slug)
+		}
+		sort.Strings(slugs)
+		textB := tx.Bucket(bFTText)
+		for _, slug := range slugs {
+			a := want[slug]
+			mb, err := json.Marshal(a.Meta)
+			if err != nil {
+				return err
+			}
+			// 正文改动不一定反映在 meta 上，还要比较全文索引里保存的纯文本
+			if bytes.Equal(metaB.Get([]byte(slug)), mb) && string(textB.Get([]byte(slug))) == a.Text {
+				stats.Unchanged++
+				continue
+			}
+			if err := upsertArticle(tx, a, opt); err != nil {
+				return err
+			}
+			changed[slug] = true
+			stats.Upserted++
+		}
+		return updateRelated(tx, changed, false, opt.Related)
+	})
+	return stats, err
+}
+
+func ensureArticleBuckets(tx *bolt.Tx) error {
+	for _, name := range articleBuckets {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upsertArticle(tx *bolt.Tx, a content.Article, opt RebuildOptions) error {
+	if strings.TrimSpace(a.Meta.Slug) == "" {
+		return nil
+	}
+	if err := deleteArticle(tx, a.Meta.Slug); err != nil {
+		return err
+	}
+	if !indexable(a.Meta, opt) {
+		return nil
+	}
+	return putArticle(tx, a)
+}
+
+// deleteArticle 按索引里保存的旧 meta 找到并删除各个索引项。
+// 旧地址和短 ID 只有仍指向该 slug 时才删除，避免误删已经转给别的文章的记录
+func deleteArticle(tx *bolt.Tx, slug string) error {
+	metaB := tx.Bucket(bMeta)
+	v := metaB.Get([]byte(slug))
+	if v == nil {
+		return nil
+	}
+	var m content.ArticleMeta
+	if err := json.Unmarshal(v, &m); err != nil {
+		return fmt.Errorf("decode meta(%s): %w", slug, err)
+	}
+
+	uKey := makeStickyTimeSlugKey(m.Sticky, m.Updated.UnixNano(), m.Slug)
+	cKey := makeStickyTimeSlugKey(m.Sticky, m.Date.UnixNano(), m.Slug)
+	if err := tx.Bucket(bIdxUpdated).Delete(uKey); err != nil {
+		return err
+	}
+	if err := tx.Bucket(bIdxCreated).Delete(cKey); err != nil {
+		return err
+	}
+
+	for _, tag := range m.Tags {
+		if err := deleteFromSub(tx.Bucket(bIdxTag), tag, uKey); err != nil {
+			return err
+		}
+	}
+	if cat := strings.TrimSpace(m.Category); cat != "" {
+		if err := deleteFromSub(tx.Bucket(bIdxCat), cat, uKey); err != nil {
+			return err
+		}
+	}
+	if sn := strings.TrimSpace(m.Series.Name); sn != "" {
+		sKey := makeSeriesKey(m.Series.Order, m.Updated.UnixNano(), m.Slug)
+		if err := deleteFromSub(tx.Bucket(bIdxSeries), sn, sKey); err != nil {
+			return err
+		}
+	}
+
+	aliasB := tx.Bucket(bAlias)
+	for _, old := range m.Aliases {
+		if err := deleteIfPointsTo(aliasB, strings.TrimSpace(old), slug); err != nil {
+			return err
+		}
+	}
+	if err := deleteIfPointsTo(tx.Bucket(bShort), strings.TrimSpace(m.ShortID), slug); err != nil {
+		return err
+	}
+	if err := deleteBacklinks(tx, m); err != nil {
+		return err
+	}
+	if err := deleteFullText(tx, slug); err != nil {
+		return err
+	}
+	return metaB.Delete([]byte(slug))
+}
+
+// deleteFromSub 删除子 bucket 里的一个键；子 bucket 空了就一起删掉，标签 / 分类列表里不会留下空项
+func deleteFromSub(parent *bolt.Bucket, name string, key []byte) error {
+	if name == "" {
+		return nil
+	}
+	sb := parent.Bucket([]byte(name))
+	if sb == nil {
+		return nil
+	}
+	if err := sb.Delete(key); err != nil {
+		return err
+	}
+	if k, _ := sb.Cursor().First(); k == nil {
+		return parent.DeleteBucket([]byte(name))
+	}
+	return nil
+}
+
+func deleteIfPointsTo(b *bolt.Bucket, key, slug string) error {
+	if key == "" || string(b.Get([]byte(key))) != slug {
+		return nil
+	}
+	return b.Delete([]byte(key))
+}
@@ -0,0 +1,197 @@
+package index
+
+import (
+	"errors"
+	"mygo/internal/domain/content"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func firstArticle() content.Article {
+	return testArticle("first", 1, func(a *content.Article) {
+		a.Meta.Tags = []string{"go", "web"}
+		a.Meta.Category = "dev"
+		a.Meta.Series = content.Series{Name: "intro", Order: 1}
+		a.Meta.Aliases = []string{"old-first"}
+		a.Meta.ShortID = "f1"
+		a.Meta.OutLinks = []string{"other", "https://example.org/x"}
+		a.Text = "gopher notes about channels"
+	})
+}
+
+func otherArticle() content.Article {
+	return testArticle("other", 2, func(a *content.Article) {
+		a.Meta.Tags = []string{"go"}
+		a.Meta.Category = "dev"
+		a.Text = "other notes about channels"
+	})
+}
+
+func upsertAll(t *testing.T, s *Store, opt RebuildOptions, arts ...content.Article) {
+	t.Helper()
+	for i := range arts {
+		if err := s.Upsert(&arts[i], opt); err != nil {
+			t.Fatalf("upsert %s: %v", arts[i].Meta.Slug, err)
+		}
+	}
+}
+
+func assertNoRefs(t *testing.T, s *Store, slug string) {
+	t.Helper()
+	if rows := refersTo(dumpBuckets(t, s), slug); len(rows) > 0 {
+		t.Errorf("index still refers to %q:", slug)
+		for _, r := range rows {
+			t.Errorf("  %s", r)
+		}
+	}
+}
+
+// bucketPostings 返回倒排表里含有 term 的 slug
+func bucketPostings(rows []row, term string) map[string]bool {
+	out := make(map[string]bool)
+	prefix := term + "\x00"
+	for _, r := range rows {
+		if r.bucket == string(bFTPosting) && strings.HasPrefix(r.key, prefix) {
+			out[r.key[len(prefix):]] = true
+		}
+	}
+	return out
+}
+
+func assertStrings(t *testing.T, what string, got, want []string) {
+	t.Helper()
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %q, want %q", what, got, want)
+	}
+}
+
+func TestUpsertRetag(t *testing.T) {
+	s := openTestStore(t)
+	opt := RebuildOptions{Related: 3}
+	upsertAll(t, s, opt, firstArticle(), otherArticle())
+
+	assertStrings(t, "tags", subBucketNames(t, s, bIdxTag), []string{"go", "web"})
+	assertStrings(t, "series", subBucketNames(t, s, bIdxSeries), []string{"intro"})
+	bl, err := s.Backlinks("other", ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStrings(t, "backlinks", slugsOf(bl), []string{"first"})
+
+	retagged := firstArticle()
+	retagged.Meta.Tags = []string{"rust"}
+	retagged.Meta.Category = "ops"
+	retagged.Meta.Series = content.Series{}
+	retagged.Meta.Aliases = []string{"old-first-2"}
+	retagged.Meta.ShortID = "f2"
+	retagged.Meta.OutLinks = nil
+	retagged.Text = "ferris notes"
+	upsertAll(t, s, opt, retagged)
+
+	rows := dumpBuckets(t, s)
+	assertStrings(t, "tags", subBucketNames(t, s, bIdxTag), []string{"go", "rust"})
+	assertStrings(t, "categories", subBucketNames(t, s, bIdxCat), []string{"dev", "ops"})
+	assertStrings(t, "series", subBucketNames(t, s, bIdxSeries), nil)
+	assertStrings(t, "alias", bucketRows(rows, bAlias), []string{"old-first-2=first"})
+	if _, err := s.GetByShortID("f1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByShortID(f1) err = %v, want ErrNotFound", err)
+	}
+	if slug, err := s.GetByShortID("f2"); err != nil || slug != "first" {
+		t.Errorf("GetByShortID(f2) = %q, %v", slug, err)
+	}
+	assertStrings(t, "backlinks", bucketRows(rows, bBacklinks), nil)
+	for _, term := range []string{"gopher", "channels"} {
+		if postings := bucketPostings(rows, term); postings["first"] {
+			t.Errorf("posting %q -> first not removed", term)
+		}
+	}
+	if !bucketPostings(rows, "ferris")["first"] {
+		t.Errorf("posting ferris -> first missing")
+	}
+	if got, err := s.ListByTag("go", ListOptions{All: true}); err != nil || !reflect.DeepEqual(slugsOf(got), []string{"other"}) {
+		t.Errorf("ListByTag(go) = %q, %v", slugsOf(got), err)
+	}
+}
+
+func TestUpsertRenameThenDelete(t *testing.T) {
+	s := openTestStore(t)
+	opt := RebuildOptions{Related: 3}
+	upsertAll(t, s, opt, firstArticle(), otherArticle())
+
+	// 改名：新 slug 接管旧地址，再由调用方删除旧 slug
+	renamed := firstArticle()
+	renamed.Meta.Slug = "renamed"
+	renamed.Meta.Aliases = []string{"old-first", "first"}
+	renamed.Meta.ShortID = "r1"
+	upsertAll(t, s, opt, renamed)
+	if err := s.Delete("first"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNoRefs(t, s, "first")
+	rows := dumpBuckets(t, s)
+	assertStrings(t, "alias", bucketRows(rows, bAlias), []string{"first=renamed", "old-first=renamed"})
+	if slug, err := s.ResolveAlias("first"); err != nil || slug != "renamed" {
+		t.Errorf("ResolveAlias(first) = %q, %v", slug, err)
+	}
+	if _, err := s.GetByShortID("f1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByShortID(f1) err = %v, want ErrNotFound", err)
+	}
+	bl, err := s.Backlinks("other", ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStrings(t, "backlinks", slugsOf(bl), []string{"renamed"})
+
+	for _, slug := range []string{"renamed", "other"} {
+		if err := s.Delete(slug); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rows := dumpBuckets(t, s); len(rows) > 0 {
+		t.Errorf("buckets not empty after deleting everything:")
+		for _, r := range rows {
+			t.Errorf("  %s", r)
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	s := openTestStore(t)
+	opt := RebuildOptions{Related: 3}
+
+	stats, err := s.Sync([]content.Article{firstArticle(), otherArticle()}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SyncStats{Upserted: 2}); stats != want {
+		t.Errorf("first sync = %+v, want %+v", stats, want)
+	}
+
+	stats, err = s.Sync([]content.Article{firstArticle(), otherArticle()}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SyncStats{Unchanged: 2}); stats != want {
+		t.Errorf("second sync = %+v, want %+v", stats, want)
+	}
+
+	// 只改正文：meta 相同，但仍要重建全文索引
+	changed := otherArticle()
+	changed.Text = "rewritten body"
+	stats, err = s.Sync([]content.Article{changed}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SyncStats{Upserted: 1, Deleted: 1}); stats != want {
+		t.Errorf("third sync = %+v, want %+v", stats, want)
+	}
+	assertNoRefs(t, s, "first")
+	if bucketPostings(dumpBuckets(t, s), "channels")["other"] {
+		t.Errorf("stale posting channels -> other")
+	}
+}
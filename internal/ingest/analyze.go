@@ -0,0 +1,248 @@
+package ingest
+
+import (
+	"bytes"
+	"mygo/internal/domain/config"
+	"mygo/internal/domain/content"
+	"mygo/internal/render"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// mdEngine 用于 ingest 阶段分析正文结构和渲染手动摘要
+var mdEngine = render.NewGoldmark()
+
+var mdParser = mdEngine.Parser()
+
+// bodyStats 是从正文 AST 中提取出的信息
+type bodyStats struct {
+	CJK   int // CJK 字符数（逐字计）
+	Latin int // 拉丁词数（按空白切分）
+
+	inWord, wordAlnum bool // count 跨相邻行内片段累积的当前词
+
+	Headings []content.Heading
+	Links    []string // 原始链接目标，之后由 resolveOutLinks 归一化
+
+	Plain     string // 不含代码块的纯文本，用于自动摘要
+	MoreAt    int    // <!--more--> 在正文中的字节偏移，没有时为 -1
+	MorePlain string // <!--more--> 之前的纯文本
+}
+
+func (s bodyStats) words() int { return s.CJK + s.Latin }
+
+// readMinutes 按两种阅读速度估算阅读时长（分钟，向上取整；有正文时至少 1 分钟）
+func (s bodyStats) readMinutes(rc config.ReadingConfig) int {
+	if s.words() == 0 {
+		return 0
+	}
+	// 两部分通分后再取整：分别截断到秒会让 301 字（300 字/分）只算 1 分钟
+	num, den := 0, 1
+	if rc.CJKPerMin > 0 {
+		num, den = s.CJK, rc.CJKPerMin
+	}
+	if rc.WordsPerMin > 0 {
+		num, den = num*rc.WordsPerMin+s.Latin*den, den*rc.WordsPerMin
+	}
+	mins := (num + den - 1) / den
+	if mins < 1 {
+		mins = 1
+	}
+	return mins
+}
+
+// moreMarker 之前的正文作为手动摘要
+const moreMarker = "<!--more-->"
+
+// analyzeBody 解析一次 markdown 正文：统计字数，收集标题、链接与摘要用的纯文本
+func analyzeBody(body []byte, rc config.ReadingConfig) bodyStats {
+	doc := mdParser.Parse(text.NewReader(body))
+
+	st := bodyStats{MoreAt: -1}
+	var plain bytes.Buffer
+	inCode := false
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			switch {
+			case n.Kind() == ast.KindCodeSpan:
+				inCode = false
+			case n.Type() == ast.TypeBlock:
+				st.endWord()
+				plain.WriteByte(' ')
+			}
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.Heading:
+			st.Headings = append(st.Headings, headingOf(v, body))
+		case *ast.Link:
+			st.Links = append(st.Links, string(v.Destination))
+		case *ast.Text:
+			seg := v.Segment.Value(body)
+			if !inCode || !rc.ExcludeCode {
+				st.count(seg)
+			}
+			plain.Write(seg)
+			if v.SoftLineBreak() || v.HardLineBreak() {
+				st.endWord()
+				plain.WriteByte(' ')
+			}
+		case *ast.String:
+			st.count(v.Value)
+			plain.Write(v.Value)
+		case *ast.AutoLink:
+			if v.AutoLinkType == ast.AutoLinkURL {
+				st.Links = append(st.Links, string(v.URL(body)))
+			}
+			st.count(v.Label(body))
+			plain.Write(v.Label(body))
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeSpan:
+			inCode = true
+		case *ast.CodeBlock, *ast.FencedCodeBlock:
+			// 代码块不进摘要
+			if !rc.ExcludeCode {
+				lines := n.Lines()
+				for i := 0; i < lines.Len(); i++ {
+					seg := lines.At(i)
+					st.count(seg.Value(body))
+					st.endWord()
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock:
+			if st.MoreAt < 0 && v.Lines().Len() > 0 && isMoreMarker(v.Lines().Value(body)) {
+				st.MoreAt = v.Lines().At(0).Start
+				st.MorePlain = collapseSpace(plain.String())
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML:
+			if st.MoreAt < 0 && v.Segments.Len() > 0 && isMoreMarker(v.Segments.Value(body)) {
+				st.MoreAt = v.Segments.At(0).Start
+				st.MorePlain = collapseSpace(plain.String())
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	st.endWord()
+	st.Plain = collapseSpace(plain.String())
+	return st
+}
+
+func isMoreMarker(b []byte) bool {
+	return strings.EqualFold(string(bytes.TrimSpace(b)), moreMarker)
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// renderExcerpt 把 <!--more--> 之前的正文渲染成 HTML。摘要显示在列表页上，
+// 相对链接和图片地址要先换算成以文章页 postURL 为基准的站内路径，页面包里的资源才能正常显示
+func renderExcerpt(src []byte, postURL string) (string, error) {
+	base, err := url.Parse(postURL)
+	if err != nil {
+		return "", err
+	}
+	doc := mdParser.Parse(text.NewReader(src))
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.Link:
+			v.Destination = rebaseURL(base, v.Destination)
+		case *ast.Image:
+			v.Destination = rebaseURL(base, v.Destination)
+		}
+		return ast.WalkContinue, nil
+	})
+	var buf bytes.Buffer
+	if err := mdEngine.Renderer().Render(&buf, src, doc); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// rebaseURL 把相对地址（含纯锚点）换算到 base 下；绝对地址和以 / 开头的站内路径原样返回
+func rebaseURL(base *url.URL, dest []byte) []byte {
+	u, err := url.Parse(string(dest))
+	if err != nil || len(dest) == 0 || u.Scheme != "" || u.Host != "" || strings.HasPrefix(u.Path, "/") {
+		return dest
+	}
+	return []byte(base.ResolveReference(u).String())
+}
+
+// summaryOf 按字符截取自动摘要，截断时补上省略号
+func summaryOf(plain string, n int) string {
+	if n <= 0 {
+		return plain
+	}
+	s := render.TruncateRunes(plain, n)
+	if len(s) == len(plain) {
+		return plain
+	}
+	return strings.TrimSpace(s) + "…"
+}
+
+// headingOf 取标题的层级、锚点 id 与纯文本
+func headingOf(h *ast.Heading, src []byte) content.Heading {
+	var id string
+	if v, ok := h.AttributeString("id"); ok {
+		switch v := v.(type) {
+		case string:
+			id = v
+		case []byte:
+			id = string(v)
+		}
+	}
+	var buf bytes.Buffer
+	_ = ast.Walk(h, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := n.(type) {
+		case *ast.Text:
+			buf.Write(v.Segment.Value(src))
+		case *ast.String:
+			buf.Write(v.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return content.Heading{Level: h.Level, ID: id, Text: buf.String()}
+}
+
+// count 统计一段文本：CJK 字符逐个计数，其余按空白切分，每段计为一个拉丁词。
+// don't、e.g.、URL 这样的片段算一个词，只有标点的片段（如单独的 - 或 ...）不计。
+// 行内元素会把一个词拆成几个片段（如 foo**bar**），所以词在遇到空白、换行或块结束时才由 endWord 收尾
+func (s *bodyStats) count(b []byte) {
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+		switch {
+		case content.IsCJK(r):
+			s.endWord()
+			s.CJK++
+		case unicode.IsSpace(r):
+			s.endWord()
+		default:
+			s.inWord = true
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				s.wordAlnum = true
+			}
+		}
+	}
+}
+
+func (s *bodyStats) endWord() {
+	if s.inWord && s.wordAlnum {
+		s.Latin++
+	}
+	s.inWord, s.wordAlnum = false, false
+}
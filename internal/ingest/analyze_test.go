@@ -0,0 +1,146 @@
+package ingest
+
+import (
+	"mygo/internal/domain/config"
+	"mygo/internal/domain/site"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeBodyCount(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		excludeCode bool
+		cjk, latin  int
+	}{
+		{"mixed", "中文 and English", false, 2, 2},
+		{"no spaces between scripts", "用Go写博客", false, 4, 1},
+		{"punctuation", "don't e.g. URL - ... 。", false, 0, 3},
+		{"inline markup", "foo**bar** *baz*", false, 0, 2},
+		{"soft line break", "line one\nline two", false, 0, 4},
+		{"blocks end words", "# 标题 title\n\n- a\n- b", false, 2, 3},
+		{"link destination", "[读 docs](https://example.com/a-b) 完", false, 2, 1},
+		{"autolink label", "see <https://example.com/x> and https://example.org", false, 0, 4},
+		{"code block counted", "text\n\n```go\nfunc main() {}\n```\n", false, 0, 3},
+		{"code block excluded", "text\n\n```go\nfunc main() {}\n```\n", true, 0, 1},
+		{"indented code excluded", "text\n\n    x := 1\n", true, 0, 1},
+		{"code span counted", "use `go build` 吧", false, 1, 3},
+		{"code span excluded", "use `go build` 吧", true, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := analyzeBody([]byte(tt.src), config.ReadingConfig{ExcludeCode: tt.excludeCode})
+			if st.CJK != tt.cjk || st.Latin != tt.latin {
+				t.Errorf("CJK, Latin = %d, %d; want %d, %d", st.CJK, st.Latin, tt.cjk, tt.latin)
+			}
+		})
+	}
+}
+
+func TestAnalyzeBodyPlain(t *testing.T) {
+	st := analyzeBody([]byte("# Title\n\nIntro [link](/x).\n\n<!--more-->\n\n```\ncode\n```\n\nrest"), config.ReadingConfig{})
+	if want := "Title Intro link. rest"; st.Plain != want {
+		t.Errorf("Plain = %q, want %q", st.Plain, want)
+	}
+	if want := "Title Intro link."; st.MorePlain != want {
+		t.Errorf("MorePlain = %q, want %q", st.MorePlain, want)
+	}
+	if len(st.Links) != 1 || st.Links[0] != "/x" {
+		t.Errorf("Links = %q, want [/x]", st.Links)
+	}
+}
+
+func TestReadMinutes(t *testing.T) {
+	rc := config.ReadingConfig{CJKPerMin: 300, WordsPerMin: 200}
+	tests := []struct {
+		cjk, latin int
+		want       int
+	}{
+		{0, 0, 0},
+		{1, 0, 1},
+		{300, 0, 1},
+		{301, 0, 2},
+		{600, 0, 2},
+		{0, 200, 1},
+		{0, 201, 2},
+		// 两部分合起来算：150/300 + 100/200 正好 1 分钟
+		{150, 100, 1},
+		{151, 100, 2},
+		{450, 300, 3},
+	}
+	for _, tt := range tests {
+		st := bodyStats{CJK: tt.cjk, Latin: tt.latin}
+		if got := st.readMinutes(rc); got != tt.want {
+			t.Errorf("readMinutes(%d CJK, %d words) = %d, want %d", tt.cjk, tt.latin, got, tt.want)
+		}
+	}
+
+	// 某种速度未配置时那部分不计时，但有正文就至少 1 分钟
+	st := bodyStats{Latin: 1000}
+	if got := st.readMinutes(config.ReadingConfig{CJKPerMin: 300}); got != 1 {
+		t.Errorf("readMinutes without words_per_min = %d, want 1", got)
+	}
+}
+
+func TestRenderExcerptRebase(t *testing.T) {
+	src := "![cover](cover.png) [next](../other/) [doc](./files/a.pdf#p2) [top](#top) " +
+		"[root](/about/) [ext](https://x.org/a) [mail](mailto:a@b.c)\n"
+	got, err := renderExcerpt([]byte(src), "/post/2024/01/02/hello/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<p><img src="/post/2024/01/02/hello/cover.png" alt="cover"> ` +
+		`<a href="/post/2024/01/02/other/">next</a> ` +
+		`<a href="/post/2024/01/02/hello/files/a.pdf#p2">doc</a> ` +
+		`<a href="/post/2024/01/02/hello/#top">top</a> ` +
+		`<a href="/about/">root</a> <a href="https://x.org/a">ext</a> <a href="mailto:a@b.c">mail</a></p>`
+	if got != want {
+		t.Errorf("renderExcerpt =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// 页面包里 <!--more--> 之前引用的图片，在列表页上也要指向文章页下的资源
+func TestIngestExcerptInBundle(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "hello"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := "---\ntitle: Hello\ndate: 2024-01-02\n---\n![c](cover.png) 开头\n\n<!--more-->\n\n后文\n"
+	if err := os.WriteFile(filepath.Join(dir, "hello", "index.md"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	arts, _, err := Ingest(Options{SourceDir: dir, Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(arts) != 1 {
+		t.Fatalf("articles = %+v, want one", arts)
+	}
+	m := arts[0].Meta
+	want := `<p><img src="` + site.PostURL(m) + `cover.png" alt="c"> 开头</p>`
+	if m.Excerpt != want || m.Summary != "c 开头" {
+		t.Errorf("excerpt, summary = %q, %q; want %q, %q", m.Excerpt, m.Summary, want, "c 开头")
+	}
+}
+
+func TestSummaryOf(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"你好世界", 4, "你好世界"},
+		{"你好世界!", 4, "你好世界…"},
+		// 截断处的空白去掉再补省略号
+		{"你好 世界 abc", 3, "你好…"},
+		{"abc", 0, "abc"},
+	}
+	for _, tt := range tests {
+		if got := summaryOf(tt.in, tt.n); got != tt.want {
+			t.Errorf("summaryOf(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DiagKind 区分 front matter 问题的类别
+type DiagKind string
+
+const (
+	DiagSyntax     DiagKind = "syntax error"
+	DiagUnknownKey DiagKind = "unknown key"
+	DiagType       DiagKind = "wrong type"
+	DiagDate       DiagKind = "invalid date"
+)
+
+// Diagnostic 是 front matter 里的一处问题；Line / Column 从 1 开始、相对整个源文件，0 表示未知
+type Diagnostic struct {
+	Line   int
+	Column int
+	Kind   DiagKind
+	Msg    string
+}
+
+func (d Diagnostic) String() string {
+	return position{d.Line, d.Column}.prefix() + string(d.Kind) + ": " + d.Msg
+}
+
+func (d Diagnostic) warning(path string) Warning {
+	return Warning{Path: path, Line: d.Line, Column: d.Column, Msg: string(d.Kind) + ": " + d.Msg}
+}
+
+// FrontMatterError 表示 front matter 无法使用（语法错误或字段类型不对），文章会被跳过
+type FrontMatterError struct {
+	Format Format
+	Diags  []Diagnostic
+}
+
+func (e *FrontMatterError) Error() string {
+	parts := make([]string, len(e.Diags))
+	for i, d := range e.Diags {
+		parts[i] = d.String()
+	}
+	return fmt.Sprintf("%s front matter: %s", e.Format, strings.Join(parts, "; "))
+}
+
+type position struct {
+	line, col int
+}
+
+// prefix 返回 "line:col: " 形式的前缀，位置未知时为空
+func (p position) prefix() string {
+	switch {
+	case p.line <= 0:
+		return ""
+	case p.col <= 0:
+		return strconv.Itoa(p.line) + ": "
+	default:
+		return strconv.Itoa(p.line) + ":" + strconv.Itoa(p.col) + ": "
+	}
+}
+
+// parseDate 解析日期字段；写错时记一条带位置的诊断，按没写处理
+func (fm *FrontMatter) parseDate(key string, v DateValue, loc *time.Location) time.Time {
+	t, err := ParseTime(string(v), loc)
+	if err != nil {
+		p := fm.positions[key]
+		fm.Diagnostics = append(fm.Diagnostics, Diagnostic{
+			Line: p.line, Column: p.col, Kind: DiagDate, Msg: key + ": " + err.Error(),
+		})
+	}
+	return t
+}
+
+// fmDecoder 逐个键解码 front matter，收集诊断与每个值在源文件中的位置
+type fmDecoder struct {
+	src   []byte // front matter 原文，不含分隔符
+	shift int    // src 第一行之前的行数
+	diags []Diagnostic
+	pos   map[string]position // 键名（嵌套键如 series.name）→ 值的位置
+}
+
+func (d *fmDecoder) add(line, col int, kind DiagKind, msg string) {
+	if line > 0 {
+		line += d.shift
+	}
+	d.diags = append(d.diags, Diagnostic{Line: line, Column: col, Kind: kind, Msg: msg})
+}
+
+func (d *fmDecoder) mark(key string, line, col int) {
+	d.pos[key] = position{line + d.shift, col}
+}
+
+// at 把 src 内的字节偏移换算成行列号（均从 1 开始，列按字符计）
+func (d *fmDecoder) at(off int) (line, col int) {
+	if off > len(d.src) {
+		off = len(d.src)
+	}
+	head := d.src[:off]
+	start := bytes.LastIndexByte(head, '\n') + 1
+	return bytes.Count(head, []byte("\n")) + 1, utf8.RuneCount(head[start:]) + 1
+}
+
+// fatal 判断已收集的诊断里是否有导致文章无法使用的问题
+func (d *fmDecoder) fatal() bool {
+	for _, dg := range d.diags {
+		if dg.Kind == DiagSyntax || dg.Kind == DiagType {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldIndex 按 tag 名索引结构体字段；三种格式的 tag 名一致，统一用 yaml tag
+func fieldIndex(t reflect.Type) map[string]int {
+	m := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		m[name] = i
+	}
+	return m
+}
+
+// isNested 判断字段是否是需要逐键解码的嵌套表（如 series）
+func isNested(v reflect.Value) bool {
+	return v.Kind() == reflect.Struct && v.Type() != reflect.TypeOf(time.Time{})
+}
+
+var yamlLineRE = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// splitYAMLErr 从 yaml.v3 的错误信息里拆出行号
+func splitYAMLErr(err error) (int, string) {
+	var te *yaml.TypeError
+	msg := err.Error()
+	if errors.As(err, &te) && len(te.Errors) > 0 {
+		msg = strings.TrimSpace(te.Errors[0])
+	}
+	if m := yamlLineRE.FindStringSubmatch(msg); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n, m[2]
+	}
+	return 0, strings.TrimPrefix(msg, "yaml: ")
+}
+
+func (d *fmDecoder) decodeYAML(fm *FrontMatter) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(d.src, &doc); err != nil {
+		line, msg := splitYAMLErr(err)
+		d.add(line, 0, DiagSyntax, msg)
+		return
+	}
+	if len(doc.Content) == 0 {
+		return
+	}
+	d.yamlMapping(doc.Content[0], reflect.ValueOf(fm).Elem(), "")
+}
+
+func (d *fmDecoder) yamlMapping(n *yaml.Node, v reflect.Value, prefix string) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		return
+	}
+	if n.Kind != yaml.MappingNode {
+		d.add(n.Line, n.Column, DiagType, describe(prefix)+" must be a mapping")
+		return
+	}
+	fields := fieldIndex(v.Type())
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, val := n.Content[i], n.Content[i+1]
+		key := prefix + k.Value
+		idx, ok := fields[k.Value]
+		if !ok {
+			d.add(k.Line, k.Column, DiagUnknownKey, strconv.Quote(key))
+			continue
+		}
+		d.mark(key, val.Line, val.Column)
+		f := v.Field(idx)
+		if isNested(f) {
+			d.yamlMapping(val, f, key+".")
+			continue
+		}
+		if err := val.Decode(f.Addr().Interface()); err != nil {
+			_, msg := splitYAMLErr(err)
+			d.add(val.Line, val.Column, DiagType, key+": "+msg)
+		}
+	}
+}
+
+var tomlErrRE = regexp.MustCompile(`^toml: (?:line (\d+) )?\(last key "([^"]*)"\): (.*)$`)
+
+func (d *fmDecoder) decodeTOML(fm *FrontMatter) {
+	md, err := toml.NewDecoder(bytes.NewReader(d.src)).Decode(fm)
+	if err != nil {
+		var pe toml.ParseError
+		if errors.As(err, &pe) {
+			// UnmarshalTOML 的错误也被包成 ParseError，而且没有 Unwrap，只能按消息识别
+			if pe.LastKey != "" && strings.HasPrefix(pe.Message, errDateType.Error()) {
+				d.add(pe.Position.Line, pe.Position.Col, DiagType, pe.LastKey+": "+pe.Message)
+				return
+			}
+			d.add(pe.Position.Line, pe.Position.Col, DiagSyntax, pe.Message)
+			return
+		}
+		// 类型不匹配是普通 error，只带 "line N (last key ...)" 前缀
+		if m := tomlErrRE.FindStringSubmatch(err.Error()); m != nil {
+			_, p := d.tomlKeyPos(m[2])
+			if p.line == 0 {
+				p.line, _ = strconv.Atoi(m[1])
+			}
+			d.add(p.line, p.col, DiagType, m[2]+": "+m[3])
+			return
+		}
+		d.add(0, 0, DiagType, strings.TrimPrefix(err.Error(), "toml: "))
+		return
+	}
+	for _, k := range md.Keys() {
+		key := k.String()
+		if _, v := d.tomlKeyPos(key); v.line > 0 {
+			d.mark(key, v.line, v.col)
+		}
+	}
+	unknown := make(map[string]bool)
+	for _, k := range md.Undecoded() {
+		unknown[k.String()] = true
+		if len(k) > 1 && unknown[k[:len(k)-1].String()] {
+			continue // 未知表下面的键只报表本身
+		}
+		p, _ := d.tomlKeyPos(k.String())
+		d.add(p.line, p.col, DiagUnknownKey, strconv.Quote(k.String()))
+	}
+}
+
+// tomlKeyPos 在原文里找 key 本身和它的值的位置；series.name 这类键先定位 [series] 表头，
+// key 是表名时两者都是表头里表名的位置。BurntSushi/toml 不公开键的位置，这里按行匹配，找不到返回零值
+func (d *fmDecoder) tomlKeyPos(key string) (k, v position) {
+	lines := strings.Split(string(d.src), "\n")
+	table, name := "", key
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		table, name = key[:i], key[i+1:]
+	}
+	cur := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			cur = strings.Trim(trimmed, "[] \t")
+			if cur == key {
+				p := position{i + 1, utf8.RuneCountInString(line[:strings.Index(line, cur)]) + 1}
+				return p, p
+			}
+			continue
+		}
+		lhs, _, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			continue
+		}
+		lhs = strings.Trim(strings.TrimSpace(lhs), `"'`)
+		switch {
+		case cur == table && lhs == name:
+		case cur == "" && lhs == key: // 点号写法：series.name = ...
+		default:
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		val := strings.IndexByte(line, '=') + 1
+		for val < len(line) && (line[val] == ' ' || line[val] == '\t') {
+			val++
+		}
+		return position{i + 1, utf8.RuneCountInString(line[:indent]) + 1},
+			position{i + 1, utf8.RuneCountInString(line[:val]) + 1}
+	}
+	return position{}, position{}
+}
+
+func (d *fmDecoder) decodeJSON(fm *FrontMatter) {
+	d.jsonObject(0, reflect.ValueOf(fm).Elem(), "")
+}
+
+// jsonObject 逐个键解码从 src[off] 开始的 JSON 对象；splitJSON 已确认过语法
+func (d *fmDecoder) jsonObject(off int, v reflect.Value, prefix string) {
+	if off >= len(d.src) || d.src[off] != '{' {
+		line, col := d.at(off)
+		if bytes.HasPrefix(d.src[off:], []byte("null")) {
+			return
+		}
+		d.add(line, col, DiagType, describe(prefix)+" must be an object")
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(d.src[off:]))
+	if _, err := dec.Token(); err != nil {
+		line, col := d.at(off)
+		d.add(line, col, DiagSyntax, err.Error())
+		return
+	}
+	fields := fieldIndex(v.Type())
+	for dec.More() {
+		keyOff := skipJSONSpace(d.src, off+int(dec.InputOffset()))
+		tok, err := dec.Token()
+		if err != nil {
+			line, col := d.at(keyOff)
+			d.add(line, col, DiagSyntax, err.Error())
+			return
+		}
+		name, _ := tok.(string)
+		key := prefix + name
+		valOff := skipJSONSpace(d.src, off+int(dec.InputOffset()))
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			line, col := d.at(valOff)
+			d.add(line, col, DiagSyntax, err.Error())
+			return
+		}
+		idx, ok := fields[name]
+		if !ok {
+			line, col := d.at(keyOff)
+			d.add(line, col, DiagUnknownKey, strconv.Quote(key))
+			continue
+		}
+		line, col := d.at(valOff)
+		d.mark(key, line, col)
+		f := v.Field(idx)
+		if isNested(f) {
+			d.jsonObject(valOff, f, key+".")
+			continue
+		}
+		if err := json.Unmarshal(raw, f.Addr().Interface()); err != nil {
+			msg := err.Error()
+			var te *json.UnmarshalTypeError
+			if errors.As(err, &te) {
+				msg = fmt.Sprintf("cannot use JSON %s as %s", te.Value, te.Type)
+			}
+			d.add(line, col, DiagType, key+": "+msg)
+		}
+	}
+}
+
+// skipJSONSpace 跳过值之间的空白和 , : 分隔符
+func skipJSONSpace(src []byte, i int) int {
+	for i < len(src) && strings.IndexByte(" \t\r\n,:", src[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+func describe(key string) string {
+	key = strings.TrimSuffix(key, ".")
+	if key == "" {
+		return "front matter"
+	}
+	return strconv.Quote(key)
+}
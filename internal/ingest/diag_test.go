@@ -0,0 +1,187 @@
+package ingest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// diagsOf 解析 src 的 front matter，返回全部诊断（包括按 pipeline 的方式解析日期时产生的）
+func diagsOf(t *testing.T, src string) []Diagnostic {
+	t.Helper()
+	fm, _, err := ParseFrontMatter([]byte(src))
+	if err != nil {
+		var fe *FrontMatterError
+		if !errors.As(err, &fe) {
+			t.Fatalf("ParseFrontMatter(%q): %v", src, err)
+		}
+		return fe.Diags
+	}
+	fm.parseDate("date", fm.Date, time.UTC)
+	fm.parseDate("updated", fm.Updated, time.UTC)
+	fm.parseDate("expires", fm.Expires, time.UTC)
+	return fm.Diagnostics
+}
+
+func TestDiagnosticPositions(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want Diagnostic // 只比较 Line / Column / Kind
+	}{
+		{"yaml syntax", "---\ntitle: ok\n  bad: indent\n---\nbody", Diagnostic{Line: 3, Kind: DiagSyntax}},
+		{"yaml unknown", "---\ntitle: x\nfoo: 1\n---\n", Diagnostic{Line: 3, Column: 1, Kind: DiagUnknownKey}},
+		{"yaml nested unknown", "---\nseries:\n  name: s\n  foo: 1\n---\n", Diagnostic{Line: 4, Column: 3, Kind: DiagUnknownKey}},
+		{"yaml flow mapping", "---\ntitle: 标题\nseries: {name: 标题, foo: 1}\n---\n", Diagnostic{Line: 3, Column: 20, Kind: DiagUnknownKey}},
+		{"yaml type", "---\nsticky: abc\n---\n", Diagnostic{Line: 2, Column: 9, Kind: DiagType}},
+		{"yaml date", "---\ndate: yesterday\n---\n", Diagnostic{Line: 2, Column: 7, Kind: DiagDate}},
+		{"yaml leading blank lines", "\n\n---\nfoo: 1\n---\n", Diagnostic{Line: 4, Column: 1, Kind: DiagUnknownKey}},
+		{"yaml crlf", "---\r\ntitle: x\r\nfoo: 1\r\n---\r\n", Diagnostic{Line: 3, Column: 1, Kind: DiagUnknownKey}},
+		{"yaml unclosed", "\n---\ntitle: x\n", Diagnostic{Line: 2, Column: 1, Kind: DiagSyntax}},
+
+		{"toml syntax", "+++\ntitle = \"x\"\nbad line\n+++\n", Diagnostic{Line: 3, Column: 5, Kind: DiagSyntax}},
+		{"toml unknown", "+++\ntitle = \"x\"\n  foo = 1\n+++\n", Diagnostic{Line: 3, Column: 3, Kind: DiagUnknownKey}},
+		{"toml table unknown", "+++\n[series]\nname = \"s\"\nfoo = 1\n+++\n", Diagnostic{Line: 4, Column: 1, Kind: DiagUnknownKey}},
+		{"toml dotted unknown", "+++\nseries.foo = 1\n+++\n", Diagnostic{Line: 2, Column: 1, Kind: DiagUnknownKey}},
+		{"toml unknown table", "+++\n[extra]\na = 1\n+++\n", Diagnostic{Line: 2, Column: 2, Kind: DiagUnknownKey}},
+		{"toml type", "+++\nsticky = \"abc\"\n+++\n", Diagnostic{Line: 2, Column: 10, Kind: DiagType}},
+		{"toml table type", "+++\n[series]\norder = \"x\"\n+++\n", Diagnostic{Line: 3, Column: 9, Kind: DiagType}},
+		{"toml date", "+++\ndate = \"nope\"\n+++\n", Diagnostic{Line: 2, Column: 8, Kind: DiagDate}},
+		{"toml date type", "+++\ntitle = \"x\"\ndate = 12\n+++\n", Diagnostic{Line: 3, Column: 8, Kind: DiagType}},
+
+		{"json unknown", "{\n  \"title\": \"x\",\n  \"foo\": 1\n}\nbody", Diagnostic{Line: 3, Column: 3, Kind: DiagUnknownKey}},
+		{"json nested unknown", "{\"series\": {\"name\": \"s\", \"foo\": 1}}\n", Diagnostic{Line: 1, Column: 26, Kind: DiagUnknownKey}},
+		{"json unknown after cjk", "{\"title\": \"标题\", \"foo\": 1}\n", Diagnostic{Line: 1, Column: 17, Kind: DiagUnknownKey}},
+		{"json type", "{\"sticky\": \"abc\"}\n", Diagnostic{Line: 1, Column: 12, Kind: DiagType}},
+		{"json nested type", "{\"series\": {\"order\": \"x\"}}\n", Diagnostic{Line: 1, Column: 22, Kind: DiagType}},
+		{"json not an object", "{\"series\": 1}\n", Diagnostic{Line: 1, Column: 12, Kind: DiagType}},
+		{"json date", "\n{\n  \"date\": \"nope\"\n}\n", Diagnostic{Line: 3, Column: 11, Kind: DiagDate}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := diagsOf(t, tt.src)
+			if len(diags) != 1 {
+				t.Fatalf("diagnostics = %v, want exactly one", diags)
+			}
+			got := diags[0]
+			got.Msg = ""
+			if got != tt.want {
+				t.Errorf("diagnostic = %d:%d %s, want %d:%d %s",
+					got.Line, got.Column, got.Kind, tt.want.Line, tt.want.Column, tt.want.Kind)
+			}
+		})
+	}
+}
+
+// splitJSON 已确认过语法，这里直接喂给解码器，检查语法错误的位置
+func TestDecodeJSONSyntax(t *testing.T) {
+	d := &fmDecoder{src: []byte("{\n  \"title\": \"x\",\n  \"tags\": [1,]\n}"), shift: 2, pos: make(map[string]position)}
+	var fm FrontMatter
+	d.decodeJSON(&fm)
+	want := []Diagnostic{{Line: 5, Column: 11, Kind: DiagSyntax}}
+	for i := range d.diags {
+		d.diags[i].Msg = ""
+	}
+	if !reflect.DeepEqual(d.diags, want) {
+		t.Errorf("diagnostics = %v, want %v", d.diags, want)
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	tests := []struct {
+		d    Diagnostic
+		want string
+	}{
+		{Diagnostic{Line: 3, Column: 7, Kind: DiagUnknownKey, Msg: `"foo"`}, `3:7: unknown key: "foo"`},
+		{Diagnostic{Line: 3, Kind: DiagSyntax, Msg: "bad"}, "3: syntax error: bad"},
+		{Diagnostic{Kind: DiagType, Msg: "bad"}, "wrong type: bad"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+	w := Diagnostic{Line: 2, Column: 1, Kind: DiagDate, Msg: "date: x"}.warning("source/a.md")
+	if got, want := w.String(), "source/a.md:2:1: invalid date: date: x"; got != want {
+		t.Errorf("warning = %q, want %q", got, want)
+	}
+}
+
+func TestValuePositions(t *testing.T) {
+	tests := []struct {
+		src  string
+		want map[string]position
+	}{
+		{"---\ndate: 2024-01-01\nseries:\n  name: s\n---\n",
+			map[string]position{"date": {2, 7}, "series": {4, 3}, "series.name": {4, 9}}},
+		{"+++\ndate = 2024-01-01\n[series]\nname = \"s\"\n+++\n",
+			map[string]position{"date": {2, 8}, "series": {3, 2}, "series.name": {4, 8}}},
+		{"{\"date\": \"2024-01-01\",\n \"series\": {\"name\": \"s\"}}\n",
+			map[string]position{"date": {1, 10}, "series": {2, 12}, "series.name": {2, 21}}},
+	}
+	for _, tt := range tests {
+		fm, _, err := ParseFrontMatter([]byte(tt.src))
+		if err != nil {
+			t.Fatalf("ParseFrontMatter(%q): %v", tt.src, err)
+		}
+		if !reflect.DeepEqual(fm.positions, tt.want) {
+			t.Errorf("positions of %q = %v, want %v", tt.src, fm.positions, tt.want)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"2024-03-05", time.Date(2024, 3, 5, 0, 0, 0, 0, shanghai), false},
+		{" 2024-03-05 09:30 ", time.Date(2024, 3, 5, 9, 30, 0, 0, shanghai), false},
+		{"2024-03-05 09:30:15", time.Date(2024, 3, 5, 9, 30, 15, 0, shanghai), false},
+		// 带偏移的写法换算到站点时区
+		{"2024-03-05T00:00:00Z", time.Date(2024, 3, 5, 8, 0, 0, 0, shanghai), false},
+		{"yesterday", time.Time{}, true},
+		{"2024-13-01", time.Time{}, true},
+		{"05/03/2024", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in, shanghai)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTime(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) || (!got.IsZero() && got.Location() != shanghai) {
+			t.Errorf("ParseTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// 写错的日期不再悄悄变成零值：Ingest 给出带位置的警告，并退回到文件修改时间
+func TestIngestReportsBadDate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.md")
+	if err := os.WriteFile(path, []byte("---\ntitle: A\ndate: 2024-13-01\n---\nbody\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	arts, warns, err := Ingest(Options{SourceDir: dir, Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(arts) != 1 || arts[0].Meta.Date.IsZero() {
+		t.Fatalf("articles = %+v, want one with a fallback date", arts)
+	}
+	var got []string
+	for _, w := range warns {
+		got = append(got, w.String())
+	}
+	want := path + `:3:7: invalid date: date: cannot parse "2024-13-01" (want YYYY-MM-DD, YYYY-MM-DD HH:MM[:SS] or RFC 3339)`
+	if len(got) == 0 || got[0] != want {
+		t.Errorf("warnings = %q, want first %q", got, want)
+	}
+}
@@ -2,12 +2,18 @@ package ingest
 
 import (
 	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 )
 
 type SourceFile struct {
 	Path string
+
+	// 页面包（page bundle）：目录下有 index.md 时整个目录是一篇文章，
+	// 其余文件作为附带资源，复制到文章输出目录旁边
+	BundleDir string
+	Assets    []string // 相对 BundleDir 的路径（斜杠分隔）
 }
 
 func DiscoverSource(root string) ([]SourceFile, error) {
@@ -18,12 +24,68 @@ func DiscoverSource(root string) ([]SourceFile, error) {
 			return err
 		}
 		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			if idx := bundleIndex(path); idx != "" {
+				sf, err := discoverBundle(path, idx)
+				if err != nil {
+					return err
+				}
+				out = append(out, sf)
+				return filepath.SkipDir
+			}
 			return nil
 		}
-		if strings.HasSuffix(strings.ToLower(d.Name()), ".md") || strings.HasSuffix(strings.ToLower(d.Name()), ".markdown") {
+		if isMarkdown(d.Name()) {
 			out = append(out, SourceFile{Path: path})
 		}
 		return nil
 	})
 	return out, err
 }
+
+func isMarkdown(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, ".md") || strings.HasSuffix(name, ".markdown")
+}
+
+// bundleIndex 返回目录下的 index.md / index.markdown，不是页面包时返回空串
+func bundleIndex(dir string) string {
+	for _, name := range []string{"index.md", "index.markdown"} {
+		p := filepath.Join(dir, name)
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+// discoverBundle 收集页面包内除 index 以外的所有文件（跳过隐藏文件）
+func discoverBundle(dir, index string) (SourceFile, error) {
+	sf := SourceFile{Path: index, BundleDir: dir}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir || path == index {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		sf.Assets = append(sf.Assets, filepath.ToSlash(rel))
+		return nil
+	})
+	return sf, err
+}
@@ -0,0 +1,84 @@
+package ingest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeSource(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, src := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+var bundleSite = map[string]string{
+	"hello/index.md":    "---\ndate: 2024-01-02\n---\n![d](diagram.png)\n",
+	"hello/diagram.png": "png",
+	"hello/img/x.png":   "png",
+	"hello/.hidden.png": "png",
+	"hello/.git/HEAD":   "ref",
+	// 与页面包同级的 .md 是单独的文章，不是 hello 的资源
+	"sibling.md": "---\ndate: 2024-01-01\n---\nbody\n",
+	// 没有 index.md 的目录不是页面包，里面的图片不会被收集
+	"notes/post.md": "---\ndate: 2024-01-03\n---\nbody\n",
+	"notes/pic.png": "png",
+}
+
+func TestDiscoverSourceBundles(t *testing.T) {
+	dir := t.TempDir()
+	writeSource(t, dir, bundleSite)
+	got, err := DiscoverSource(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SourceFile{
+		{
+			Path:      filepath.Join(dir, "hello", "index.md"),
+			BundleDir: filepath.Join(dir, "hello"),
+			Assets:    []string{"diagram.png", "img/x.png"},
+		},
+		{Path: filepath.Join(dir, "notes", "post.md")},
+		{Path: filepath.Join(dir, "sibling.md")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiscoverSource =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+// bundleRef 只保留测试关心的页面包字段
+type bundleRef struct {
+	BundleDir string
+	Assets    []string
+}
+
+func TestIngestBundleSlug(t *testing.T) {
+	dir := t.TempDir()
+	writeSource(t, dir, bundleSite)
+	arts, _, err := Ingest(Options{SourceDir: dir, Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bySlug := make(map[string]bundleRef)
+	for _, a := range arts {
+		bySlug[a.Meta.Slug] = bundleRef{a.Body.BundleDir, a.Body.Assets}
+	}
+	want := map[string]bundleRef{
+		// 没有 slug / title 时页面包用目录名，而不是 "index"
+		"hello":   {filepath.Join(dir, "hello"), []string{"diagram.png", "img/x.png"}},
+		"sibling": {},
+		"post":    {},
+	}
+	if !reflect.DeepEqual(bySlug, want) {
+		t.Errorf("articles = %+v, want %+v", bySlug, want)
+	}
+}
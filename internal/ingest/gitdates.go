@@ -0,0 +1,164 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitDatesFile 是 git 日期缓存在 CacheDir 下的文件名
+const GitDatesFile = "gitdates.json"
+
+// gitDates 记录每个源文件（相对 source 目录、斜杠分隔）第一次和最后一次提交的时间
+type gitDates struct {
+	Head  string                 `json:"head"`
+	Files map[string]gitFileDate `json:"files"`
+}
+
+type gitFileDate struct {
+	First int64 `json:"first"` // unix 秒
+	Last  int64 `json:"last"`
+}
+
+// lookup 返回源文件的首次 / 最后提交时间，不在 git 历史中时 ok 为 false
+func (g *gitDates) lookup(sourceDir, path string, loc *time.Location) (first, last time.Time, ok bool) {
+	if g == nil {
+		return
+	}
+	rel, err := filepath.Rel(sourceDir, path)
+	if err != nil {
+		return
+	}
+	d, ok := g.Files[filepath.ToSlash(rel)]
+	if !ok {
+		return
+	}
+	return time.Unix(d.First, 0).In(loc), time.Unix(d.Last, 0).In(loc), true
+}
+
+// loadGitDates 读取 sourceDir 所在 git 仓库的提交时间。
+// 结果按 HEAD 缓存到 cacheDir：HEAD 没变直接用缓存；新 HEAD 是缓存 HEAD 的后代时只扫描新增的提交
+func loadGitDates(sourceDir, cacheDir string) (*gitDates, error) {
+	head, err := runGit(sourceDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	head = strings.TrimSpace(head)
+
+	var cachePath string
+	if cacheDir != "" {
+		cachePath = filepath.Join(cacheDir, GitDatesFile)
+	}
+	cached := readGitDatesCache(cachePath)
+	if cached != nil && cached.Head == head {
+		return cached, nil
+	}
+
+	rev := head
+	g := &gitDates{Files: make(map[string]gitFileDate)}
+	if cached != nil && isAncestor(sourceDir, cached.Head, head) {
+		g.Files = cached.Files
+		rev = cached.Head + ".." + head
+	}
+	if err := scanGitLog(sourceDir, rev, g.Files); err != nil {
+		return nil, err
+	}
+	g.Head = head
+
+	if cachePath != "" {
+		if err := writeGitDatesCache(cachePath, g); err != nil {
+			return g, fmt.Errorf("write git dates cache: %w", err)
+		}
+	}
+	return g, nil
+}
+
+// scanGitLog 遍历 rev 范围内的提交（新到旧，取作者时间），更新每个文件的首次 / 最后提交时间
+func scanGitLog(sourceDir, rev string, files map[string]gitFileDate) error {
+	out, err := runGit(sourceDir,
+		"-c", "core.quotepath=off",
+		"log", "--format=%x1e%at", "--name-only", "--no-renames", "--relative", rev, "--", ".",
+	)
+	if err != nil {
+		return err
+	}
+
+	var ts int64
+	sc := bufio.NewScanner(strings.NewReader(out))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "\x1e") {
+			ts, err = strconv.ParseInt(strings.TrimSpace(line[1:]), 10, 64)
+			if err != nil {
+				return fmt.Errorf("git log: bad timestamp %q", line[1:])
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		d, seen := files[line]
+		if !seen || ts > d.Last {
+			d.Last = ts
+		}
+		if !seen || ts < d.First {
+			d.First = ts
+		}
+		files[line] = d
+	}
+	return sc.Err()
+}
+
+func isAncestor(dir, ancestor, rev string) bool {
+	_, err := runGit(dir, "merge-base", "--is-ancestor", ancestor, rev)
+	return err == nil
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+func readGitDatesCache(path string) *gitDates {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var g gitDates
+	if err := json.Unmarshal(data, &g); err != nil || g.Head == "" || g.Files == nil {
+		return nil
+	}
+	return &g
+}
+
+func writeGitDatesCache(path string, g *gitDates) error {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
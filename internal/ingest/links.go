@@ -0,0 +1,102 @@
+package ingest
+
+import (
+	"mygo/internal/domain/content"
+	"mygo/internal/domain/site"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// linkTargets 记录站内文章能被哪些地址引用到
+type linkTargets struct {
+	bySource map[string]string // 源文件路径 -> slug
+	byPath   map[string]string // 文章页 / alias / 短链的站内路径 -> slug
+	siteHost string
+}
+
+func newLinkTargets(arts []content.Article, siteURL string) *linkTargets {
+	t := &linkTargets{
+		bySource: make(map[string]string, len(arts)),
+		byPath:   make(map[string]string, len(arts)),
+	}
+	if u, err := url.Parse(strings.TrimSpace(siteURL)); err == nil {
+		t.siteHost = u.Host
+	}
+	for _, a := range arts {
+		m := a.Meta
+		t.bySource[filepath.Clean(a.Body.SourcePath)] = m.Slug
+		t.byPath[site.PostURL(m)] = m.Slug
+		for _, al := range m.Aliases {
+			if p := site.AliasPath(m, al); p != "" {
+				t.byPath[p] = m.Slug
+			}
+		}
+		if p := site.ShortURL(m); p != "" {
+			t.byPath[p] = m.Slug
+		}
+	}
+	return t
+}
+
+// resolveOutLinks 把 ingest 阶段收集到的原始链接归一化：指向站内文章的换成 slug，
+// 其余链接保留原样；纯锚点和指向自身的链接丢弃，结果去重并保持原顺序
+func resolveOutLinks(arts []content.Article, siteURL string) {
+	t := newLinkTargets(arts, siteURL)
+	for i := range arts {
+		a := &arts[i]
+		seen := make(map[string]struct{}, len(a.Meta.OutLinks))
+		var out []string
+		for _, raw := range a.Meta.OutLinks {
+			l := t.resolve(*a, raw)
+			if l == "" || l == a.Meta.Slug {
+				continue
+			}
+			if _, ok := seen[l]; ok {
+				continue
+			}
+			seen[l] = struct{}{}
+			out = append(out, l)
+		}
+		a.Meta.OutLinks = out
+	}
+}
+
+func (t *linkTargets) resolve(from content.Article, raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.HasPrefix(raw, "#") {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	if u.Scheme != "" || u.Host != "" {
+		// 写成绝对地址的本站链接也按站内路径处理
+		if t.siteHost == "" || u.Host != t.siteHost {
+			return raw
+		}
+	}
+	p, err := url.PathUnescape(u.Path)
+	if err != nil || p == "" {
+		return raw
+	}
+
+	if !strings.HasPrefix(p, "/") {
+		switch strings.ToLower(path.Ext(p)) {
+		case ".md", ".markdown":
+			// 直接引用另一篇 markdown 源文件
+			src := filepath.Join(filepath.Dir(from.Body.SourcePath), filepath.FromSlash(p))
+			if slug, ok := t.bySource[filepath.Clean(src)]; ok {
+				return slug
+			}
+			return raw
+		}
+		p = path.Join(site.PostURL(from.Meta), p)
+	}
+	if slug, ok := t.byPath[site.CleanPath(p)]; ok {
+		return slug
+	}
+	return raw
+}
@@ -2,8 +2,10 @@ package ingest
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
-	"gopkg.in/yaml.v3"
+	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -13,81 +15,134 @@ import (
 
 var errNoFrontMatter = errors.New("no front matter found")
 var errInvalidFrontMatter = errors.New("invalid front matter")
+var errDateType = errors.New("expected a string or datetime")
 
 type FrontMatter struct {
-	Title   string `yaml:"title"`
-	Slug    string `yaml:"slug"`
-	Date    string `yaml:"date"`
-	Updated string `yaml:"updated"`
+	Title       string    `yaml:"title" toml:"title" json:"title"`
+	Slug        string    `yaml:"slug" toml:"slug" json:"slug"`
+	Description string    `yaml:"description" toml:"description" json:"description"`
+	Date        DateValue `yaml:"date" toml:"date" json:"date"`
+	Updated     DateValue `yaml:"updated" toml:"updated" json:"updated"`
+	Expires     DateValue `yaml:"expires" toml:"expires" json:"expires"`
 
-	Tags     []string `yaml:"tags"`
-	Category string   `yaml:"category"`
+	Tags     []string `yaml:"tags" toml:"tags" json:"tags"`
+	Category string   `yaml:"category" toml:"category" json:"category"`
 
-	Sticky int    `yaml:"sticky"`
-	Hidden bool   `yaml:"hidden"`
-	Draft  bool   `yaml:"draft"`
-	Cover  string `yaml:"cover"`
+	Sticky  int    `yaml:"sticky" toml:"sticky" json:"sticky"`
+	Hidden  bool   `yaml:"hidden" toml:"hidden" json:"hidden"`
+	Draft   bool   `yaml:"draft" toml:"draft" json:"draft"`
+	NoIndex bool   `yaml:"noindex" toml:"noindex" json:"noindex"`
+	Cover   string `yaml:"cover" toml:"cover" json:"cover"`
 
-	Aliases []string `yaml:"aliases"`
+	Aliases []string `yaml:"aliases" toml:"aliases" json:"aliases"`
 	Series  struct {
-		Name  string `yaml:"name"`
-		Order int    `yaml:"order"`
-	} `yaml:"series"`
+		Name  string `yaml:"name" toml:"name" json:"name"`
+		Order int    `yaml:"order" toml:"order" json:"order"`
+	} `yaml:"series" toml:"series" json:"series"`
 
-	ShortID string `yaml:"short"`
+	ShortID string `yaml:"short" toml:"short" json:"short"`
+
+	// Diagnostics 是解析时发现的非致命问题（未知键、无法识别的日期）
+	Diagnostics []Diagnostic        `yaml:"-" toml:"-" json:"-"`
+	positions   map[string]position // 各个键的值在源文件中的位置
 }
 
-func ParseFrontMatter(raw []byte) (FrontMatter, []byte, error) {
-	raw = bytes.TrimSpace(raw)
-	if len(raw) == 0 {
-		return FrontMatter{}, raw, errNoFrontMatter
+// DateValue 是 front matter 里日期的原文，统一交给 ParseTime 解析。
+// TOML 允许不加引号的日期时间，解码时转回对应格式的字符串
+type DateValue string
+
+func (d *DateValue) UnmarshalTOML(v any) error {
+	switch t := v.(type) {
+	case string:
+		*d = DateValue(t)
+	case time.Time:
+		switch t.Location().String() {
+		case "date-local":
+			*d = DateValue(t.Format(time.DateOnly))
+		case "datetime-local":
+			*d = DateValue(t.Format(time.DateTime))
+		default:
+			*d = DateValue(t.Format(time.RFC3339))
+		}
+	default:
+		return fmt.Errorf("%w, got %T", errDateType, v)
 	}
+	return nil
+}
+
+// Format 是 front matter 的写法
+type Format string
+
+const (
+	FormatYAML Format = "yaml" // --- ... ---
+	FormatTOML Format = "toml" // +++ ... +++
+	FormatJSON Format = "json" // { ... }
+)
 
+// ParseFrontMatter 识别 YAML / TOML / JSON 三种 front matter，映射到同一个 FrontMatter 上，
+// 返回去掉 front matter 后的正文。语法错误和类型错误返回 *FrontMatterError；
+// 未知键等不影响使用的问题记在 fm.Diagnostics 里
+func ParseFrontMatter(raw []byte) (FrontMatter, []byte, error) {
 	// 统一换行符
 	norm := bytes.ReplaceAll(raw, []byte("\r\n"), []byte("\n"))
 	norm = bytes.ReplaceAll(norm, []byte("\r"), []byte("\n"))
+	trimmed := bytes.TrimLeft(norm, " \t\n")
+	lead := bytes.Count(norm[:len(norm)-len(trimmed)], []byte("\n"))
+	norm = bytes.TrimSpace(trimmed)
 
-	const (
-		sep      = "---"
-		sepLine  = sep + "\n"
-		closeMid = "\n" + sep + "\n"
-	)
-
-	if !bytes.HasPrefix(norm, []byte(sepLine)) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
 		return FrontMatter{}, raw, errNoFrontMatter
 	}
 
-	// 去掉首行 "---\n"
-	rest := norm[len(sepLine):]
-
-	var yamlPart, bodyPart []byte
-
-	// 优先走最常见的情况：中间有 "\n---\n"
-	if parts := bytes.SplitN(rest, []byte(closeMid), 2); len(parts) == 2 {
-		yamlPart = parts[0]
-		bodyPart = parts[1]
-	} else {
-		// 可能是结尾是 "\n---" 且无正文
-		if bytes.HasSuffix(rest, []byte("\n"+sep)) {
-			yamlPart = rest[:len(rest)-len("\n"+sep)]
-			bodyPart = nil
-		} else if bytes.Equal(bytes.TrimSpace(rest), []byte(sep)) {
-			// 处理 "---\n---" 这种“空 front matter，无正文”
-			yamlPart = nil
-			bodyPart = nil
-		} else {
-			return FrontMatter{}, raw, errInvalidFrontMatter
+	var (
+		format         Format
+		head, bodyPart []byte
+		err            error
+	)
+	switch {
+	case bytes.HasPrefix(norm, []byte("---\n")):
+		format = FormatYAML
+		head, bodyPart, err = splitDelimited(norm, "---")
+		lead++ // 分隔符本身占一行
+	case bytes.HasPrefix(norm, []byte("+++\n")):
+		format = FormatTOML
+		head, bodyPart, err = splitDelimited(norm, "+++")
+		lead++
+	case norm[0] == '{':
+		// 正文也可能以 { 开头（JSON 示例、代码），只有完整的对象单独占据开头几行时才算 front matter
+		var ok bool
+		if head, bodyPart, ok = splitJSON(norm); !ok {
+			return FrontMatter{}, raw, errNoFrontMatter
 		}
+		format = FormatJSON
+	default:
+		return FrontMatter{}, raw, errNoFrontMatter
+	}
+	if err != nil {
+		return FrontMatter{}, raw, &FrontMatterError{Format: format, Diags: []Diagnostic{splitDiag(format, lead, err)}}
 	}
 
-	yamlPart = bytes.TrimSpace(yamlPart)
+	// 只去掉结尾空白，保持行号与源文件一致
+	head = bytes.TrimRight(head, " \t\n")
 	bodyPart = bytes.TrimSpace(bodyPart)
 
 	var fm FrontMatter
-	if len(yamlPart) > 0 {
-		if err := yaml.Unmarshal(yamlPart, &fm); err != nil {
-			return FrontMatter{}, raw, err
+	if len(bytes.TrimSpace(head)) > 0 {
+		d := &fmDecoder{src: head, shift: lead, pos: make(map[string]position)}
+		switch format {
+		case FormatTOML:
+			d.decodeTOML(&fm)
+		case FormatJSON:
+			d.decodeJSON(&fm)
+		default:
+			d.decodeYAML(&fm)
+		}
+		if d.fatal() {
+			return FrontMatter{}, raw, &FrontMatterError{Format: format, Diags: d.diags}
 		}
+		fm.Diagnostics = d.diags
+		fm.positions = d.pos
 	}
 	if fm.Cover == "" {
 		fm.Cover = "https://cdn.example.com/default-cover.jpg"
@@ -96,6 +151,70 @@ func ParseFrontMatter(raw []byte) (FrontMatter, []byte, error) {
 	return fm, bodyPart, nil
 }
 
+// splitDiag 把切分 front matter 时的错误转换成诊断
+func splitDiag(format Format, lead int, err error) Diagnostic {
+	if errors.Is(err, errInvalidFrontMatter) {
+		sep := "---"
+		if format == FormatTOML {
+			sep = "+++"
+		}
+		return Diagnostic{Line: lead, Column: 1, Kind: DiagSyntax, Msg: "missing closing " + sep}
+	}
+	return Diagnostic{Kind: DiagSyntax, Msg: err.Error()}
+}
+
+// splitDelimited 切分以 sep 单独成行包围的 front matter
+func splitDelimited(norm []byte, sep string) (head, body []byte, err error) {
+	// 去掉首行分隔符
+	rest := norm[len(sep)+1:]
+
+	// 优先走最常见的情况：中间有 "\n<sep>\n"
+	if parts := bytes.SplitN(rest, []byte("\n"+sep+"\n"), 2); len(parts) == 2 {
+		return parts[0], parts[1], nil
+	}
+	// 可能是结尾是 "\n<sep>" 且无正文
+	if bytes.HasSuffix(rest, []byte("\n"+sep)) {
+		return rest[:len(rest)-len("\n"+sep)], nil, nil
+	}
+	// 空 front matter："---\n---" 或 "---\n---\n正文"
+	if bytes.Equal(rest, []byte(sep)) {
+		return nil, nil, nil
+	}
+	if bytes.HasPrefix(rest, []byte(sep+"\n")) {
+		return nil, rest[len(sep)+1:], nil
+	}
+	return nil, nil, errInvalidFrontMatter
+}
+
+// splitJSON 切出开头的 JSON 对象，剩下的部分是正文。
+// 对象不完整，或同一行后面还有别的内容时返回 false，整个文件按没有 front matter 处理
+func splitJSON(norm []byte) (head, body []byte, ok bool) {
+	dec := json.NewDecoder(bytes.NewReader(norm))
+	var obj json.RawMessage
+	if err := dec.Decode(&obj); err != nil {
+		return nil, nil, false
+	}
+	end := int(dec.InputOffset())
+	rest := norm[end:]
+	if line, _, _ := bytes.Cut(rest, []byte("\n")); len(bytes.TrimSpace(line)) > 0 {
+		return nil, nil, false
+	}
+	return norm[:end], rest, true
+}
+
+// ReadBody 读取源文件并切掉 front matter，只返回 markdown 正文
+func ReadBody(path string) ([]byte, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	_, body, fmErr := ParseFrontMatter(src)
+	if fmErr != nil {
+		body = src
+	}
+	return body, nil
+}
+
 func ResolveSlug(fm FrontMatter, path string) string {
 	if s := strings.TrimSpace(fm.Slug); s != "" {
 		return slugify(s)
@@ -104,12 +223,23 @@ func ResolveSlug(fm FrontMatter, path string) string {
 		return slugify(t)
 	}
 	base := filepath.Base(path)
-	return slugify(strings.TrimSuffix(base, filepath.Ext(base)))
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	if strings.EqualFold(name, "index") {
+		// 页面包的 index.md 用目录名
+		name = filepath.Base(filepath.Dir(path))
+	}
+	return slugify(name)
 }
 
-func ParseTime(s string) time.Time {
+// ParseTime 按站点时区解析 front matter 里的时间；带时区偏移的写法也统一换算到该时区。
+// 空字符串返回零值，无法识别的写法返回错误
+func ParseTime(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
 	if s == "" {
-		return time.Time{}
+		return time.Time{}, nil
+	}
+	if loc == nil {
+		loc = time.Local
 	}
 	for _, layout := range []string{
 		time.RFC3339,
@@ -117,11 +247,11 @@ func ParseTime(s string) time.Time {
 		"2006-01-02 15:04",
 		time.DateTime,
 	} {
-		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
-			return t
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t.In(loc), nil
 		}
 	}
-	return time.Time{}
+	return time.Time{}, fmt.Errorf("cannot parse %q (want YYYY-MM-DD, YYYY-MM-DD HH:MM[:SS] or RFC 3339)", s)
 }
 
 func slugify(s string) string {
@@ -0,0 +1,127 @@
+package ingest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFrontMatterFormats(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		title     string
+		body      string
+		noFM      bool // errNoFrontMatter，正文是整个文件
+		wantError bool // *FrontMatterError
+	}{
+		{name: "yaml", src: "---\ntitle: Y\n---\n\nbody", title: "Y", body: "body"},
+		{name: "yaml crlf", src: "---\r\ntitle: Y\r\n---\r\nbody", title: "Y", body: "body"},
+		{name: "yaml no body", src: "---\ntitle: Y\n---", title: "Y", body: ""},
+		{name: "yaml empty", src: "---\n---\nbody", body: "body"},
+		{name: "yaml leading blank lines", src: "\n\n---\ntitle: Y\n---\nbody", title: "Y", body: "body"},
+		{name: "toml", src: "+++\ntitle = \"T\"\n+++\nbody", title: "T", body: "body"},
+		{name: "json", src: "{\n  \"title\": \"J\"\n}\nbody", title: "J", body: "body"},
+		{name: "json one line", src: "{\"title\": \"J\"}\n\nbody", title: "J", body: "body"},
+		{name: "json no body", src: "{\"title\": \"J\"}", title: "J", body: ""},
+		{name: "json braces in strings", src: "{\"title\": \"a } b {\", \"tags\": [\"{x}\"]}\nbody {", title: "a } b {", body: "body {"},
+
+		{name: "plain markdown", src: "# Hello\n\nbody", noFM: true},
+		{name: "thematic break is not yaml", src: "---title\nbody", noFM: true},
+		{name: "json text after object", src: "{\"title\": \"J\"} is an object\nbody", noFM: true},
+		{name: "json body", src: "{not json}\nbody", noFM: true},
+		{name: "json array", src: "[1, 2]\nbody", noFM: true},
+		{name: "json unterminated", src: "{\"title\": \"J\"\nbody", noFM: true},
+		{name: "json unterminated string", src: "{\"title\": \"J}\nbody", noFM: true},
+
+		{name: "yaml unterminated", src: "---\ntitle: Y\nbody", wantError: true},
+		{name: "toml unterminated", src: "+++\ntitle = \"T\"\nbody", wantError: true},
+		{name: "mixed delimiters", src: "---\ntitle: Y\n+++\nbody", wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := ParseFrontMatter([]byte(tt.src))
+			var fe *FrontMatterError
+			switch {
+			case tt.noFM:
+				if !errors.Is(err, errNoFrontMatter) {
+					t.Fatalf("err = %v, want errNoFrontMatter", err)
+				}
+				if string(body) != tt.src {
+					t.Errorf("body = %q, want the whole file", body)
+				}
+				return
+			case tt.wantError:
+				if !errors.As(err, &fe) {
+					t.Fatalf("err = %v, want *FrontMatterError", err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("err = %v", err)
+			}
+			if fm.Title != tt.title || string(body) != tt.body {
+				t.Errorf("title, body = %q, %q; want %q, %q", fm.Title, body, tt.title, tt.body)
+			}
+		})
+	}
+}
+
+func TestTOMLDates(t *testing.T) {
+	tests := []struct {
+		src  string
+		want DateValue
+	}{
+		{`date = 2024-01-02`, "2024-01-02"},
+		{`date = "2024-01-02"`, "2024-01-02"},
+		{`date = 2024-01-02T10:30:00`, "2024-01-02 10:30:00"},
+		{`date = 2024-01-02 10:30:00`, "2024-01-02 10:30:00"},
+		{`date = "2024-01-02 10:30"`, "2024-01-02 10:30"},
+		{`date = 2024-01-02T10:30:00+08:00`, "2024-01-02T10:30:00+08:00"},
+		{`date = 2024-01-02T10:30:00Z`, "2024-01-02T10:30:00Z"},
+	}
+	for _, tt := range tests {
+		fm, _, err := ParseFrontMatter([]byte("+++\n" + tt.src + "\n+++\nbody"))
+		if err != nil {
+			t.Errorf("%s: %v", tt.src, err)
+			continue
+		}
+		if fm.Date != tt.want {
+			t.Errorf("%s: date = %q, want %q", tt.src, fm.Date, tt.want)
+		}
+		if _, err := ParseTime(string(fm.Date), nil); err != nil {
+			t.Errorf("%s: %v", tt.src, err)
+		}
+	}
+
+	var fe *FrontMatterError
+	if _, _, err := ParseFrontMatter([]byte("+++\ndate = 12\n+++\n")); !errors.As(err, &fe) || fe.Diags[0].Kind != DiagType {
+		t.Errorf("integer date: err = %v, want a type error", err)
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		src, want string
+	}{
+		{"+++\ndraft = true\n+++\nbody", "body"},
+		{"{\"unknown\": 1}\nbody", "body"},
+		{"no front matter", "no front matter"},
+		// front matter 无法使用时整个文件都当作正文，与 ingest 跳过文章时的提示一致
+		{"---\nsticky: x\n---\nbody", "---\nsticky: x\n---\nbody"},
+	}
+	for i, tt := range tests {
+		p := filepath.Join(dir, "post"+string(rune('a'+i))+".md")
+		if err := os.WriteFile(p, []byte(tt.src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadBody(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("ReadBody(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
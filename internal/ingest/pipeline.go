@@ -1,7 +1,11 @@
 package ingest
 
 import (
+	"errors"
+	"fmt"
+	"mygo/internal/domain/config"
 	"mygo/internal/domain/content"
+	"mygo/internal/domain/site"
 	"os"
 	"runtime"
 	"strings"
@@ -10,9 +14,31 @@ import (
 )
 
 type Warning struct {
-	Path string
-	Msg  string
+	Path   string
+	Line   int // 从 1 开始，0 表示不针对某一行
+	Column int
+	Msg    string
 }
+
+// String 按 "path:line:col: msg" 的形式输出，编辑器和 CI 都能直接跳转
+func (w Warning) String() string {
+	if w.Line <= 0 {
+		return w.Path + ": " + w.Msg
+	}
+	return w.Path + ":" + position{w.Line, w.Column}.prefix() + w.Msg
+}
+
+// ErrStrict 表示 strict 模式下出现了警告
+var ErrStrict = errors.New("warnings are treated as errors in strict mode")
+
+// CheckStrict 在 strict 模式下把警告转换成错误
+func CheckStrict(strict bool, warns []Warning) error {
+	if !strict || len(warns) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d warning(s)", ErrStrict, len(warns))
+}
+
 type Result struct {
 	Article content.Article
 	Warns   []Warning
@@ -22,14 +48,52 @@ type Result struct {
 
 type Options struct {
 	SourceDir string
+	SiteURL   string // 用于识别写成绝对地址的站内链接
+	Reading   config.ReadingConfig
+	Summary   config.SummaryConfig
+	Now       time.Time // 判定定时 / 过期文章的基准时间，零值表示当前时间
+
+	Location *time.Location // 站点时区，nil 表示本机时区
+
+	GitDates bool   // front matter 没写日期时用 git 首次 / 最后提交时间
+	CacheDir string // git 日期缓存所在目录，空表示不缓存
+}
+
+// OptionsFromConfig 从站点配置取出 ingest 需要的选项
+func OptionsFromConfig(cfg config.Config) Options {
+	return Options{
+		SourceDir: cfg.Build.SourceDir,
+		SiteURL:   cfg.Site.SiteURL,
+		Reading:   cfg.Reading,
+		Summary:   cfg.Summary,
+		Now:       cfg.Build.Now,
+		Location:  cfg.Site.Location(),
+		GitDates:  cfg.Build.GitDates,
+	}
 }
 
-func Ingest(sourceDir string) ([]content.Article, []Warning, error) {
-	files, err := DiscoverSource(sourceDir)
+func Ingest(opt Options) ([]content.Article, []Warning, error) {
+	if opt.Now.IsZero() {
+		opt.Now = time.Now()
+	}
+	if opt.Location == nil {
+		opt.Location = time.Local
+	}
+	files, err := DiscoverSource(opt.SourceDir)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	var gd *gitDates
+	var warns []Warning
+	if opt.GitDates {
+		gd, err = loadGitDates(opt.SourceDir, opt.CacheDir)
+		if err != nil {
+			// 不是 git 仓库等情况：退回到文件修改时间
+			warns = append(warns, Warning{Path: opt.SourceDir, Msg: "git dates unavailable: " + err.Error()})
+		}
+	}
+
 	workers := runtime.GOMAXPROCS(0)
 	jobs := make(chan SourceFile)
 	results := make(chan Result)
@@ -53,14 +117,19 @@ func Ingest(sourceDir string) ([]content.Article, []Warning, error) {
 				}
 				contentHash := HashBytes(raw)
 
-				fm, _, fmErr := ParseFrontMatter(raw)
+				fm, body, fmErr := ParseFrontMatter(raw)
 
 				var warns []Warning
 				if fmErr != nil && fmErr != errNoFrontMatter {
-					warns = append(warns, Warning{
-						Path: sf.Path,
-						Msg:  "failed to parse front matter: " + fmErr.Error(),
-					})
+					var fe *FrontMatterError
+					if !errors.As(fmErr, &fe) {
+						results <- Result{Err: fmt.Errorf("parse front matter(%s): %w", sf.Path, fmErr)}
+						continue
+					}
+					for _, d := range fe.Diags {
+						warns = append(warns, d.warning(sf.Path))
+					}
+					warns = append(warns, Warning{Path: sf.Path, Msg: "invalid " + string(fe.Format) + " front matter, post skipped"})
 					results <- Result{Warns: warns, Skip: true}
 					continue
 				}
@@ -75,20 +144,36 @@ func Ingest(sourceDir string) ([]content.Article, []Warning, error) {
 					continue
 				}
 				meta := content.ArticleMeta{
-					Title:    fm.Title,
-					Slug:     slug,
-					Tags:     fm.Tags,
-					Category: fm.Category,
-					Sticky:   fm.Sticky,
-					Hidden:   fm.Hidden,
-					Draft:    fm.Draft,
-					Cover:    fm.Cover,
-					Aliases:  fm.Aliases,
+					Title:       fm.Title,
+					Slug:        slug,
+					Description: strings.TrimSpace(fm.Description),
+					Tags:        fm.Tags,
+					Category:    fm.Category,
+					Sticky:      fm.Sticky,
+					Hidden:      fm.Hidden,
+					Draft:       fm.Draft,
+					NoIndex:     fm.NoIndex,
+					Cover:       fm.Cover,
+					Aliases:     fm.Aliases,
+					ShortID:     strings.TrimSpace(fm.ShortID),
 				}
 				meta.Series = content.Series{Name: fm.Series.Name, Order: fm.Series.Order}
-				mt := st.ModTime().In(time.Local)
-				meta.Date = ParseTime(fm.Date)
-				meta.Updated = ParseTime(fm.Updated)
+				mt := st.ModTime().In(opt.Location)
+				meta.Date = fm.parseDate("date", fm.Date, opt.Location)
+				meta.Updated = fm.parseDate("updated", fm.Updated, opt.Location)
+				meta.Expires = fm.parseDate("expires", fm.Expires, opt.Location)
+				for _, d := range fm.Diagnostics {
+					warns = append(warns, d.warning(sf.Path))
+				}
+				if first, last, ok := gd.lookup(opt.SourceDir, sf.Path, opt.Location); ok {
+					// front matter 优先，git 只补缺失的日期
+					if meta.Date.IsZero() {
+						meta.Date = first
+					}
+					if meta.Updated.IsZero() {
+						meta.Updated = last
+					}
+				}
 				if meta.Date.IsZero() {
 					meta.Date = mt
 					warns = append(warns, Warning{
@@ -99,9 +184,27 @@ func Ingest(sourceDir string) ([]content.Article, []Warning, error) {
 				if meta.Updated.IsZero() {
 					meta.Updated = meta.Date
 				}
+				meta.Scheduled = meta.Date.After(opt.Now)
+				meta.Expired = !meta.Expires.IsZero() && !meta.Expires.After(opt.Now)
 				if strings.TrimSpace(meta.Title) == "" {
 					warns = append(warns, Warning{Path: sf.Path, Msg: "title is empty"})
 				}
+				stats := analyzeBody(body, opt.Reading)
+				meta.WordCount = stats.words()
+				meta.ReadMin = stats.readMinutes(opt.Reading)
+				meta.Headings = stats.Headings
+				meta.OutLinks = stats.Links // 全部文章收集完后再归一化
+				if stats.MoreAt >= 0 {
+					excerpt, err := renderExcerpt(body[:stats.MoreAt], site.PostURL(meta))
+					if err != nil {
+						results <- Result{Err: fmt.Errorf("render excerpt(%s): %w", sf.Path, err)}
+						continue
+					}
+					meta.Excerpt = excerpt
+					meta.Summary = stats.MorePlain
+				} else {
+					meta.Summary = summaryOf(stats.Plain, opt.Summary.Length)
+				}
 				meta.Normalize()
 				results <- Result{
 					Article: content.Article{
@@ -109,7 +212,10 @@ func Ingest(sourceDir string) ([]content.Article, []Warning, error) {
 						Body: content.BodyRef{
 							SourcePath:  sf.Path,
 							ContentHash: contentHash,
+							BundleDir:   sf.BundleDir,
+							Assets:      sf.Assets,
 						},
+						Text: stats.Plain,
 					},
 					Warns: warns,
 				}
@@ -127,7 +233,6 @@ func Ingest(sourceDir string) ([]content.Article, []Warning, error) {
 	}()
 
 	var out []content.Article
-	var warns []Warning
 	for r := range results {
 		if r.Err != nil {
 			return nil, nil, r.Err
@@ -150,5 +255,6 @@ func Ingest(sourceDir string) ([]content.Article, []Warning, error) {
 		seen[a.Meta.Slug] = struct{}{}
 		filtered = append(filtered, a)
 	}
+	resolveOutLinks(filtered, opt.SiteURL)
 	return filtered, warns, nil
 }
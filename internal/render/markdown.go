@@ -10,12 +10,18 @@ import (
 	"github.com/yuin/goldmark/text"
 )
 
+// RendererVersion 标识 markdown 渲染管线；修改扩展或渲染选项时需要同步修改，
+// 增量构建据此判断是否要重新渲染所有文章
+const RendererVersion = "goldmark-gfm-linkify-1"
+
 type MarkdownRenderer struct {
 	md goldmark.Markdown
 }
 
-func NewMarkdownRenderer() *MarkdownRenderer {
-	md := goldmark.New(
+// NewGoldmark 返回站点统一的 goldmark 配置；ingest 分析正文、渲染手动摘要也用它，
+// 与文章页的渲染结果保持一致
+func NewGoldmark() goldmark.Markdown {
+	return goldmark.New(
 		goldmark.WithExtensions(
 			extension.GFM,
 			extension.Linkify,
@@ -25,7 +31,10 @@ func NewMarkdownRenderer() *MarkdownRenderer {
 		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
 		goldmark.WithRendererOptions(html.WithUnsafe()),
 	)
-	return &MarkdownRenderer{md: md}
+}
+
+func NewMarkdownRenderer() *MarkdownRenderer {
+	return &MarkdownRenderer{md: NewGoldmark()}
 }
 
 type MarkdownResult struct {
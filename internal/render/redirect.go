@@ -0,0 +1,35 @@
+package render
+
+import (
+	"bytes"
+	"html/template"
+)
+
+var redirectTpl = template.Must(template.New("redirect").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8" />
+    <title>Redirecting…</title>
+    <link rel="canonical" href="{{ .Canonical }}" />
+    <meta name="robots" content="noindex" />
+    <meta http-equiv="refresh" content="0; url={{ .Target }}" />
+</head>
+<body>
+<p>Redirecting to <a href="{{ .Target }}">{{ .Target }}</a></p>
+</body>
+</html>
+`))
+
+// RedirectHTML 生成静态跳转页（meta refresh + canonical），用于 alias / 短链等旧地址。
+// target 是浏览器跳转的地址，canonical 是给搜索引擎的绝对地址。
+func RedirectHTML(target, canonical string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := redirectTpl.Execute(&buf, struct {
+		Target    string
+		Canonical string
+	}{target, canonical})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
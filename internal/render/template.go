@@ -5,7 +5,7 @@ import (
 	"context"
 	"fmt"
 	"html/template"
-	"mygo/internal/domain/content"
+	"mygo/internal/domain/site"
 	"os"
 	"path/filepath"
 	"time"
@@ -15,16 +15,20 @@ type TemplateRenderer struct {
 	tpl *template.Template
 }
 
-func NewTemplateRenderer(themeDir, themeName string) (*TemplateRenderer, error) {
+// NewTemplateRenderer 加载主题模板；loc 是站点时区，date / nowYear 按它格式化，nil 表示本机时区
+func NewTemplateRenderer(themeDir, themeName string, loc *time.Location) (*TemplateRenderer, error) {
+	if loc == nil {
+		loc = time.Local
+	}
 	pattern := filepath.Join(themeDir, themeName, "templates", "*tmpl")
-	tpl, err := template.New("").Funcs(templateFuncs()).ParseGlob(pattern)
+	tpl, err := template.New("").Funcs(templateFuncs(loc)).ParseGlob(pattern)
 	if err != nil {
 		return nil, err
 	}
 	return &TemplateRenderer{tpl: tpl}, nil
 }
 
-func templateFuncs() template.FuncMap {
+func templateFuncs(loc *time.Location) template.FuncMap {
 	return template.FuncMap{
 		"date": func(t interface{}, layout string) string {
 			switch v := t.(type) {
@@ -32,6 +36,8 @@ func templateFuncs() template.FuncMap {
 				return ""
 			case string:
 				return v
+			case time.Time:
+				return v.In(loc).Format(layout)
 			case interface{ Format(string) string }:
 				return v.Format(layout)
 			default:
@@ -39,16 +45,21 @@ func templateFuncs() template.FuncMap {
 			}
 		},
 		"nowYear": func() int {
-			return time.Now().Year()
+			return time.Now().In(loc).Year()
 		},
-		"postURL": func(m content.ArticleMeta) string {
-			d := m.Date
-			return fmt.Sprintf("/post/%04d/%02d/%02d/%s/",
-				d.Year(), int(d.Month()), d.Day(), m.Slug,
-			)
+		"postURL":  site.PostURL,
+		"shortURL": site.ShortURL,
+		"absURL":   site.AbsURL,
+		// noIndex 只有文章页会带 front matter 的 noindex，其它页面一律 false
+		"noIndex": func(page interface{}) bool {
+			if p, ok := page.(PostPage); ok {
+				return p.Meta.NoIndex
+			}
+			return false
 		},
-		"add": func(a, b int) int { return a + b },
-		"sub": func(a, b int) int { return a - b },
+		"excerpt": Excerpt,
+		"add":     func(a, b int) int { return a + b },
+		"sub":     func(a, b int) int { return a - b },
 	}
 }
 
@@ -0,0 +1,35 @@
+package render
+
+import (
+	"html/template"
+	"mygo/internal/domain/content"
+	"unicode/utf8"
+)
+
+// TruncateRunes 按字符（而不是字节）截断，避免切坏多字节的中文
+func TruncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos]
+		}
+		i++
+	}
+	return s
+}
+
+// Excerpt 返回列表页展示用的摘要：优先 <!--more--> 手动摘要（HTML），
+// 其次 front matter 的 description，最后是自动截取的纯文本摘要
+func Excerpt(m content.ArticleMeta) template.HTML {
+	switch {
+	case m.Excerpt != "":
+		return template.HTML(m.Excerpt)
+	case m.Description != "":
+		return template.HTML(template.HTMLEscapeString(m.Description))
+	default:
+		return template.HTML(template.HTMLEscapeString(m.Summary))
+	}
+}
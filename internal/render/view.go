@@ -4,6 +4,7 @@ import (
 	"html/template"
 	"mygo/internal/domain/config"
 	"mygo/internal/domain/content"
+	"mygo/internal/domain/site"
 	"time"
 )
 
@@ -22,9 +23,49 @@ type PostPage struct {
 	SeriesName string
 	SeriesList []content.ArticleMeta
 
-	Related []content.ArticleMeta
-	IsDraft bool
-	Title   string
+	Related     []content.ArticleMeta // 按相关度排列，由索引预先算好
+	Backlinks   []content.ArticleMeta // 链接到本文的文章，从新到旧
+	IsDraft     bool
+	IsScheduled bool // 定时发布、尚未到发布时间（serve 预览或 build -future）
+	Title       string
+}
+
+// Pager 是分页列表页给模板用的翻页信息
+type Pager struct {
+	Page       int
+	PageSize   int
+	Total      int // 条目总数
+	TotalPages int
+	BaseURL    string // 第一页的地址，如 /tags/go/
+	PrevURL    string // 没有上一页时为空
+	NextURL    string // 没有下一页时为空
+}
+
+func NewPager(base string, page, size, total int) Pager {
+	if size <= 0 {
+		size = 1
+	}
+	pages := (total + size - 1) / size
+	if pages < 1 {
+		pages = 1
+	}
+	if page < 1 {
+		page = 1
+	}
+	p := Pager{
+		Page:       page,
+		PageSize:   size,
+		Total:      total,
+		TotalPages: pages,
+		BaseURL:    base,
+	}
+	if page > 1 {
+		p.PrevURL = site.PageURL(base, page-1)
+	}
+	if page < pages {
+		p.NextURL = site.PageURL(base, page+1)
+	}
+	return p
 }
 
 type ListPage struct {
@@ -35,6 +76,7 @@ type ListPage struct {
 	Page      int
 	PageSize  int
 	Total     int
+	Pager     Pager
 	Tag       string
 	Category  string
 	Generated time.Time
@@ -47,6 +89,7 @@ type SeriesPage struct {
 	Items  []content.ArticleMeta
 	Count  int
 	Latest time.Time
+	Pager  Pager
 	Title  string
 }
 
@@ -80,13 +123,15 @@ type HomePage struct {
 	Items     []HomeItem
 	Page      int
 	PageSize  int
+	Pager     Pager
 	Generated time.Time
 	Title     string
 }
 
 type NotFoundPage struct {
-	Site config.SiteConfig
-	Path string
+	Site  config.SiteConfig
+	Path  string
+	Title string
 }
 
 type ArchivesGroup struct {
@@ -0,0 +1,114 @@
+package search
+
+import (
+	"encoding/json"
+	"mygo/internal/domain/config"
+	"mygo/internal/domain/content"
+	"mygo/internal/domain/site"
+	"mygo/internal/index"
+	"mygo/internal/render"
+)
+
+// FileName 是前端 app.js 拉取的搜索索引文件名
+const FileName = "search_index.json"
+
+const defaultExcerptRunes = 300
+
+type Entry struct {
+	Title    string   `json:"title"`
+	Slug     string   `json:"slug"`
+	URL      string   `json:"url"`
+	Date     string   `json:"date"`
+	Tags     []string `json:"tags"`
+	Category string   `json:"category,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+	Content  string   `json:"content"`
+	Words    int      `json:"word_count"`
+	ReadMin  int      `json:"read_min"`
+}
+
+type Options struct {
+	Sort          config.SortMode
+	IncludeDraft  bool
+	IncludeFuture bool
+	ExcerptRunes  int // 正文纯文本截取长度（按字符），<=0 用默认值
+}
+
+// Build 从索引中取出可见文章（已按 hidden / draft 过滤），生成搜索条目。
+// sources 为 slug -> Article，正文取 ingest 阶段得到的纯文本，不再重新读取源文件。
+func Build(
+	st *index.Store,
+	sources map[string]content.Article,
+	opt Options,
+) ([]Entry, error) {
+	if opt.ExcerptRunes <= 0 {
+		opt.ExcerptRunes = defaultExcerptRunes
+	}
+	metas, err := st.List(index.ListOptions{
+		Sort:          opt.Sort,
+		All:           true,
+		IncludeDraft:  opt.IncludeDraft,
+		IncludeFuture: opt.IncludeFuture,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(metas))
+	for _, m := range metas {
+		e := Entry{
+			Title:    m.Title,
+			Slug:     m.Slug,
+			URL:      site.PostURL(m),
+			Date:     m.Date.Format("2006-01-02"),
+			Tags:     m.Tags,
+			Category: m.Category,
+			Summary:  m.Summary,
+			Words:    m.WordCount,
+			ReadMin:  m.ReadMin,
+		}
+		if e.Tags == nil {
+			e.Tags = []string{}
+		}
+		if a, ok := sources[m.Slug]; ok {
+			e.Content = render.TruncateRunes(a.Text, opt.ExcerptRunes)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Marshal 生成 search_index.json 的内容
+func Marshal(entries []Entry) ([]byte, error) {
+	return json.Marshal(entries)
+}
+
+// Result 是 /api/search 返回的一条结果
+type Result struct {
+	Title   string   `json:"title"`
+	URL     string   `json:"url"`
+	Date    string   `json:"date"`
+	Tags    []string `json:"tags"`
+	Snippet string   `json:"snippet"` // HTML，命中词用 <mark> 包裹
+	Score   float64  `json:"score"`
+}
+
+// Results 把索引的全文搜索结果转换成接口输出
+func Results(hits []index.SearchHit) []Result {
+	out := make([]Result, 0, len(hits))
+	for _, h := range hits {
+		r := Result{
+			Title:   h.Meta.Title,
+			URL:     site.PostURL(h.Meta),
+			Date:    h.Meta.Date.Format("2006-01-02"),
+			Tags:    h.Meta.Tags,
+			Snippet: h.Snippet.HTML(),
+			Score:   h.Score,
+		}
+		if r.Tags == nil {
+			r.Tags = []string{}
+		}
+		out = append(out, r)
+	}
+	return out
+}
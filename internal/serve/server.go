@@ -2,19 +2,24 @@ package serve
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/fsnotify/fsnotify"
 	"html/template"
 	"log"
 	"mygo/internal/domain/config"
 	"mygo/internal/domain/content"
+	"mygo/internal/domain/site"
+	"mygo/internal/feed"
 	"mygo/internal/index"
 	"mygo/internal/ingest"
 	"mygo/internal/render"
+	"mygo/internal/search"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -28,8 +33,10 @@ type Server struct {
 	md        *render.MarkdownRenderer
 	tpl       render.Renderer
 
-	mu       sync.RWMutex
-	articles map[string]content.Article
+	mu         sync.RWMutex
+	articles   map[string]content.Article
+	aliases    map[string]string // 旧地址路径 -> slug
+	searchJSON []byte
 
 	sseMu     sync.Mutex
 	sseConns  map[chan string]struct{}
@@ -39,7 +46,7 @@ type Server struct {
 
 func New(cfg config.Config, indexPath string, themeDir, themeName string) (*Server, error) {
 	md := render.NewMarkdownRenderer()
-	tpl, err := render.NewTemplateRenderer(themeDir, themeName)
+	tpl, err := render.NewTemplateRenderer(themeDir, themeName, cfg.Site.Location())
 	if err != nil {
 		return nil, fmt.Errorf("serve: failed to create template renderer: %w", err)
 	}
@@ -47,6 +54,9 @@ func New(cfg config.Config, indexPath string, themeDir, themeName string) (*Serv
 	if err != nil {
 		return nil, fmt.Errorf("serve: failed to open index: %w", err)
 	}
+	if reason := st.ResetReason(); reason != nil {
+		log.Printf("[warn] %s: %v", indexPath, reason)
+	}
 
 	s := &Server{
 		cfg:       cfg,
@@ -55,6 +65,7 @@ func New(cfg config.Config, indexPath string, themeDir, themeName string) (*Serv
 		md:        md,
 		tpl:       tpl,
 		articles:  make(map[string]content.Article),
+		aliases:   make(map[string]string),
 		sseConns:  make(map[chan string]struct{}),
 	}
 	return s, nil
@@ -88,9 +99,14 @@ func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
 	mux.HandleFunc("/series/", s.handleSeries)
 	mux.HandleFunc("/tags/", s.handleTag)
 	mux.HandleFunc("/categories/", s.handleCategory)
+	mux.HandleFunc("/s/", s.handleShort)
 	mux.HandleFunc("/archives", s.handleArchives)
 	mux.HandleFunc("/tags", s.handleTagsRoot)
 	mux.HandleFunc("/categories", s.handleCategoriesRoot)
+	mux.HandleFunc("/"+search.FileName, s.handleSearchIndex)
+	mux.HandleFunc("/api/search", s.handleSearchAPI)
+	mux.HandleFunc("/"+feed.RSSFile, s.handleFeed(feed.RSS, "application/rss+xml"))
+	mux.HandleFunc("/"+feed.AtomFile, s.handleFeed(feed.Atom, "application/atom+xml"))
 
 	mux.HandleFunc("/about", s.handleStaticSlug("about"))
 	mux.HandleFunc("/links", s.handleStaticSlug("links"))
@@ -124,30 +140,66 @@ func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
 func (s *Server) rebuild(ctx context.Context) error {
 	sourceDir := s.cfg.Build.SourceDir
 	log.Printf("[serve] ingest from %s ...", sourceDir)
-	arts, warns, err := ingest.Ingest(sourceDir)
+	// serve 常驻运行，定时 / 过期按每次重建时的当前时间判定
+	opt := ingest.OptionsFromConfig(s.cfg)
+	opt.Now = time.Now()
+	opt.CacheDir = filepath.Dir(s.indexPath)
+	arts, warns, err := ingest.Ingest(opt)
 	if err != nil {
 		return fmt.Errorf("ingest: %w", err)
 	}
 	for _, w := range warns {
-		log.Printf("[warn] %s: %s", w.Path, w.Msg)
+		log.Printf("[warn] %s", w)
 	}
 	log.Printf("[serve] ingested %d articles", len(arts))
 
-	if err := s.idx.Rebuild(arts, index.RebuildOptions{
-		IncludeDraft: true,
-	}); err != nil {
-		return fmt.Errorf("index rebuild: %w", err)
+	// 只改动变化了的文章，保存一篇文章不会重写整个索引
+	stats, err := s.idx.Sync(arts, index.RebuildOptions{
+		IncludeDraft:  true,
+		IncludeFuture: true,
+		Related:       s.cfg.Related.Count,
+	})
+	if err != nil {
+		return fmt.Errorf("index sync: %w", err)
 	}
+	log.Printf("[serve] index: %d updated, %d removed, %d unchanged", stats.Upserted, stats.Deleted, stats.Unchanged)
 
 	m := make(map[string]content.Article, len(arts))
 	for _, a := range arts {
-		if strings.TrimSpace(a.Meta.Slug) == "" {
+		// 过期文章与 build 一致：直接下线
+		if strings.TrimSpace(a.Meta.Slug) == "" || a.Meta.Expired {
 			continue
 		}
 		m[a.Meta.Slug] = a
 	}
+	aliases := make(map[string]string)
+	for _, a := range m {
+		for _, alias := range a.Meta.Aliases {
+			p := site.AliasPath(a.Meta, alias)
+			if p == "" || p == "/" {
+				continue
+			}
+			aliases[p] = a.Meta.Slug
+		}
+	}
+
+	entries, err := search.Build(s.idx, m, search.Options{
+		Sort:          s.cfg.Site.SortMode,
+		IncludeDraft:  true,
+		IncludeFuture: true,
+	})
+	if err != nil {
+		return fmt.Errorf("search index: %w", err)
+	}
+	searchJSON, err := search.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("search index: %w", err)
+	}
+
 	s.mu.Lock()
 	s.articles = m
+	s.aliases = aliases
+	s.searchJSON = searchJSON
 	s.mu.Unlock()
 
 	log.Printf("[serve] rebuild complete")
@@ -270,23 +322,35 @@ func (s *Server) broadcastSSE(msg string) {
 	}
 }
 
+// 首页：/ 或 /page/N/
 func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
+	pageNo := 1
 	if r.URL.Path != "/" {
-		s.handleNotFound(w, r)
-		return
+		rest, n, ok := splitPage(strings.Trim(r.URL.Path, "/"))
+		if !ok || rest != "" {
+			s.handleNotFound(w, r)
+			return
+		}
+		pageNo = n
 	}
 
 	opt := index.ListOptions{
-		Sort:         s.cfg.Site.SortMode,
-		Page:         1,
-		Size:         20,
-		IncludeDraft: true,
+		Sort:          s.cfg.Site.SortMode,
+		Page:          pageNo,
+		Size:          s.cfg.Paginate.Home,
+		IncludeDraft:  true,
+		IncludeFuture: true,
 	}
-	items, err := s.idx.HomeItems(opt)
+	items, total, err := s.idx.HomeItems(opt)
 	if err != nil {
 		http.Error(w, "home query error", http.StatusInternalServerError)
 		return
 	}
+	pager := render.NewPager("/", pageNo, opt.Size, total)
+	if pageNo > pager.TotalPages {
+		s.handleNotFound(w, r)
+		return
+	}
 
 	var viewItems []render.HomeItem
 	for _, it := range items {
@@ -319,8 +383,9 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 	page := render.HomePage{
 		Site:      s.cfg.Site,
 		Items:     viewItems,
-		Page:      1,
+		Page:      pageNo,
 		PageSize:  opt.Size,
+		Pager:     pager,
 		Generated: time.Now(),
 		Title:     "Home",
 	}
@@ -346,81 +411,118 @@ func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
 		s.handleNotFound(w, r)
 		return
 	}
-	slug := parts[len(parts)-1]
+	slug := parts[3]
 
 	s.mu.RLock()
 	art, ok := s.articles[slug]
 	s.mu.RUnlock()
+	if len(parts) > 4 {
+		// /post/YYYY/MM/DD/slug/<asset>：页面包里的资源
+		if ok && s.serveBundleAsset(w, r, art, strings.Join(parts[4:], "/")) {
+			return
+		}
+		s.handleNotFound(w, r)
+		return
+	}
 	if !ok {
+		// 旧 slug：301 到当前地址
+		if cur, err := s.idx.ResolveAlias(slug); err == nil && s.redirectToPost(w, r, cur) {
+			return
+		}
 		s.handleNotFound(w, r)
 		return
 	}
-	meta := art.Meta
+	pp, err := s.postPage(art)
+	if err != nil {
+		log.Printf("[serve] %v", err)
+		http.Error(w, "render post error", http.StatusInternalServerError)
+		return
+	}
 
-	src, err := os.ReadFile(art.Body.SourcePath)
+	htmlBytes, err := s.tpl.RenderPost(r.Context(), pp)
 	if err != nil {
-		log.Printf("read source error: %v", err)
-		http.Error(w, "read source error", http.StatusInternalServerError)
+		log.Printf("render post error: %v", err)
+		http.Error(w, "render post error", http.StatusInternalServerError)
 		return
 	}
-	_, body, fmErr := ingest.ParseFrontMatter(src)
-	if fmErr != nil {
-		body = src
+	writeHTML(w, htmlBytes)
+}
+
+// postPage 读取源文件并组装文章页数据；文章页和 /about 这类独立页面共用，与 build 输出的内容一致
+func (s *Server) postPage(art content.Article) (render.PostPage, error) {
+	meta := art.Meta
+	body, err := ingest.ReadBody(art.Body.SourcePath)
+	if err != nil {
+		return render.PostPage{}, fmt.Errorf("read source(%s): %w", meta.Slug, err)
 	}
 
 	mdResult, err := s.md.Render(body)
 	if err != nil {
-		log.Printf("markdown render error: %v", err)
-		http.Error(w, "markdown render error", http.StatusInternalServerError)
-		return
+		return render.PostPage{}, fmt.Errorf("render markdown(%s): %w", meta.Slug, err)
 	}
 
 	var seriesList []content.ArticleMeta
 	if meta.Series.Name != "" {
 		seriesList, _ = s.idx.ListSeries(meta.Series.Name, index.ListOptions{
-			Sort:         s.cfg.Site.SortMode,
-			Page:         1,
-			Size:         1000,
-			IncludeDraft: true,
+			Sort:          s.cfg.Site.SortMode,
+			All:           true,
+			IncludeDraft:  true,
+			IncludeFuture: true,
 		})
 	}
 
-	pp := render.PostPage{
-		Site:       s.cfg.Site,
-		Meta:       meta,
-		HTML:       template.HTML(mdResult.HTML),
-		TOC:        mdResult.Headings,
-		IsDraft:    meta.Draft,
-		SeriesName: meta.Series.Name,
-		SeriesList: seriesList,
-		Title:      meta.Title,
+	related, err := s.idx.Related(meta.Slug, index.ListOptions{IncludeDraft: true, IncludeFuture: true})
+	if err != nil {
+		log.Printf("[serve] related posts(%s): %v", meta.Slug, err)
 	}
-
-	htmlBytes, err := s.tpl.RenderPost(r.Context(), pp)
+	backlinks, err := s.idx.Backlinks(meta.Slug, index.ListOptions{IncludeDraft: true, IncludeFuture: true})
 	if err != nil {
-		log.Printf("render post error: %v", err)
-		http.Error(w, "render post error", http.StatusInternalServerError)
-		return
+		log.Printf("[serve] backlinks(%s): %v", meta.Slug, err)
+	}
+
+	return render.PostPage{
+		Site:        s.cfg.Site,
+		Meta:        meta,
+		HTML:        template.HTML(mdResult.HTML),
+		TOC:         mdResult.Headings,
+		IsDraft:     meta.Draft,
+		IsScheduled: meta.Scheduled,
+		SeriesName:  meta.Series.Name,
+		SeriesList:  seriesList,
+		Related:     related,
+		Backlinks:   backlinks,
+		Title:       meta.Title,
+	}, nil
+}
+
+// serveBundleAsset 输出页面包里登记过的资源文件，不存在时返回 false
+func (s *Server) serveBundleAsset(w http.ResponseWriter, r *http.Request, art content.Article, rel string) bool {
+	for _, a := range art.Body.Assets {
+		if a == rel {
+			http.ServeFile(w, r, filepath.Join(art.Body.BundleDir, filepath.FromSlash(rel)))
+			return true
+		}
 	}
-	writeHTML(w, htmlBytes)
+	return false
 }
 
-// 系列页：/series/<name>/
+// 系列页：/series/<name>/ 或 /series/<name>/page/N/
 func (s *Server) handleSeries(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/series/")
-	path = strings.TrimSuffix(path, "/")
-	if path == "" {
+	name, pageNo, ok := splitPage(strings.Trim(strings.TrimPrefix(r.URL.Path, "/series/"), "/"))
+	if !ok || name == "" {
 		s.handleNotFound(w, r)
 		return
 	}
-	name := path
+	name = s.pathName(name, seriesNames)
 
-	items, err := s.idx.ListSeries(name, index.ListOptions{
-		Sort:         s.cfg.Site.SortMode,
-		Page:         1,
-		Size:         1000,
-		IncludeDraft: true,
-	})
+	opt := index.ListOptions{
+		Sort:          s.cfg.Site.SortMode,
+		Page:          pageNo,
+		Size:          s.cfg.Paginate.Series,
+		IncludeDraft:  true,
+		IncludeFuture: true,
+	}
+	items, err := s.idx.ListSeries(name, opt)
 	if err != nil || len(items) == 0 {
 		s.handleNotFound(w, r)
 		return
@@ -437,6 +539,7 @@ func (s *Server) handleSeries(w http.ResponseWriter, r *http.Request) {
 		Items:  items,
 		Count:  sum.Count,
 		Latest: sum.LatestUpdated,
+		Pager:  render.NewPager("/series/"+site.PathSegment(name)+"/", pageNo, opt.Size, sum.Count),
 	}
 	htmlBytes, err := s.tpl.RenderSeries(r.Context(), sp)
 	if err != nil {
@@ -447,33 +550,69 @@ func (s *Server) handleSeries(w http.ResponseWriter, r *http.Request) {
 	writeHTML(w, htmlBytes)
 }
 
-// 标签页：/tags/<tag>/
+// pathName 把 URL 里的一段还原成标签 / 分类 / 系列名。分页链接与 build 的输出目录一致，
+// 用的是 site.PathSegment 转换后的名字；原样写名字的链接也照常可用
+func (s *Server) pathName(seg string, names func(content.ArticleMeta) []string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	match := ""
+	for _, a := range s.articles {
+		for _, n := range names(a.Meta) {
+			if strings.EqualFold(n, seg) {
+				return n
+			}
+			if site.PathSegment(n) == seg && (match == "" || n < match) {
+				match = n
+			}
+		}
+	}
+	if match == "" {
+		return seg
+	}
+	return match
+}
+
+func tagNames(m content.ArticleMeta) []string { return m.Tags }
+
+func categoryNames(m content.ArticleMeta) []string { return []string{m.Category} }
+
+func seriesNames(m content.ArticleMeta) []string { return []string{m.Series.Name} }
+
+// 标签页：/tags/<tag>/ 或 /tags/<tag>/page/N/
 func (s *Server) handleTag(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/tags/")
-	path = strings.TrimSuffix(path, "/")
-	if path == "" {
+	tag, pageNo, ok := splitPage(strings.Trim(strings.TrimPrefix(r.URL.Path, "/tags/"), "/"))
+	if !ok || tag == "" {
 		s.handleNotFound(w, r)
 		return
 	}
-	tag := path
+	tag = s.pathName(tag, tagNames)
 
-	items, err := s.idx.ListByTag(tag, index.ListOptions{
-		Sort:         s.cfg.Site.SortMode,
-		Page:         1,
-		Size:         1000,
-		IncludeDraft: true,
-	})
+	opt := index.ListOptions{
+		Sort:          s.cfg.Site.SortMode,
+		Page:          pageNo,
+		Size:          s.cfg.Paginate.List,
+		IncludeDraft:  true,
+		IncludeFuture: true,
+	}
+	items, err := s.idx.ListByTag(tag, opt)
 	if err != nil || len(items) == 0 {
 		s.handleNotFound(w, r)
 		return
 	}
+	total, err := s.idx.CountByTag(tag, opt)
+	if err != nil {
+		http.Error(w, "tag query error", http.StatusInternalServerError)
+		return
+	}
 
 	lp := render.ListPage{
 		Site:      s.cfg.Site,
 		Title:     fmt.Sprintf("Tag: %s", tag),
 		Items:     items,
-		Page:      1,
-		PageSize:  len(items),
+		Page:      pageNo,
+		PageSize:  opt.Size,
+		Total:     total,
+		Pager:     render.NewPager("/tags/"+site.PathSegment(tag)+"/", pageNo, opt.Size, total),
 		Tag:       tag,
 		Generated: time.Now(),
 	}
@@ -486,33 +625,41 @@ func (s *Server) handleTag(w http.ResponseWriter, r *http.Request) {
 	writeHTML(w, htmlBytes)
 }
 
-// 分类页：/categories/<cat>/
+// 分类页：/categories/<cat>/ 或 /categories/<cat>/page/N/
 func (s *Server) handleCategory(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/categories/")
-	path = strings.TrimSuffix(path, "/")
-	if path == "" {
+	cat, pageNo, ok := splitPage(strings.Trim(strings.TrimPrefix(r.URL.Path, "/categories/"), "/"))
+	if !ok || cat == "" {
 		s.handleNotFound(w, r)
 		return
 	}
-	cat := path
+	cat = s.pathName(cat, categoryNames)
 
-	items, err := s.idx.ListByCategory(cat, index.ListOptions{
-		Sort:         s.cfg.Site.SortMode,
-		Page:         1,
-		Size:         1000,
-		IncludeDraft: true,
-	})
+	opt := index.ListOptions{
+		Sort:          s.cfg.Site.SortMode,
+		Page:          pageNo,
+		Size:          s.cfg.Paginate.List,
+		IncludeDraft:  true,
+		IncludeFuture: true,
+	}
+	items, err := s.idx.ListByCategory(cat, opt)
 	if err != nil || len(items) == 0 {
 		s.handleNotFound(w, r)
 		return
 	}
+	total, err := s.idx.CountByCategory(cat, opt)
+	if err != nil {
+		http.Error(w, "category query error", http.StatusInternalServerError)
+		return
+	}
 
 	lp := render.ListPage{
 		Site:      s.cfg.Site,
 		Title:     fmt.Sprintf("Category: %s", cat),
 		Items:     items,
-		Page:      1,
-		PageSize:  len(items),
+		Page:      pageNo,
+		PageSize:  opt.Size,
+		Total:     total,
+		Pager:     render.NewPager("/categories/"+site.PathSegment(cat)+"/", pageNo, opt.Size, total),
 		Category:  cat,
 		Generated: time.Now(),
 	}
@@ -525,10 +672,124 @@ func (s *Server) handleCategory(w http.ResponseWriter, r *http.Request) {
 	writeHTML(w, htmlBytes)
 }
 
+func (s *Server) handleSearchIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	data := s.searchJSON
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(data)
+}
+
+// /api/search?q=...&limit=N：在索引里做全文搜索，浏览器不必下载整份 search_index.json
+func (s *Server) handleSearchAPI(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, 100)
+	}
+
+	hits, err := s.idx.Search(q, index.SearchOptions{
+		Limit:         limit,
+		IncludeDraft:  true,
+		IncludeFuture: true,
+	})
+	if err != nil {
+		log.Printf("[serve] search %q: %v", q, err)
+		http.Error(w, "search failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_ = json.NewEncoder(w).Encode(struct {
+		Query   string          `json:"query"`
+		Results []search.Result `json:"results"`
+	}{q, search.Results(hits)})
+}
+
+func (s *Server) handleFeed(
+	gen func(config.SiteConfig, []feed.Item) ([]byte, error),
+	contentType string,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		sources := s.articles
+		s.mu.RUnlock()
+
+		items, err := feed.Collect(s.idx, s.md, sources, s.cfg, feed.Options{
+			IncludeDraft:  true,
+			IncludeFuture: true,
+		})
+		if err != nil {
+			log.Printf("feed query error: %v", err)
+			http.Error(w, "feed query error", http.StatusInternalServerError)
+			return
+		}
+		data, err := gen(s.cfg.Site, items)
+		if err != nil {
+			log.Printf("render feed error: %v", err)
+			http.Error(w, "render feed error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+		_, _ = w.Write(data)
+	}
+}
+
+// 短链：/s/<id>/，302 到文章当前地址
+func (s *Server) handleShort(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/s/"), "/")
+	if id == "" {
+		s.handleNotFound(w, r)
+		return
+	}
+	slug, err := s.idx.GetByShortID(id)
+	if err != nil {
+		s.handleNotFound(w, r)
+		return
+	}
+	s.mu.RLock()
+	art, ok := s.articles[slug]
+	s.mu.RUnlock()
+	if !ok {
+		s.handleNotFound(w, r)
+		return
+	}
+	http.Redirect(w, r, site.PostURL(art.Meta), http.StatusFound)
+}
+
+// redirectToPost 对已知文章发出 301，文章不存在时返回 false
+func (s *Server) redirectToPost(w http.ResponseWriter, r *http.Request, slug string) bool {
+	s.mu.RLock()
+	art, ok := s.articles[slug]
+	s.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	http.Redirect(w, r, site.PostURL(art.Meta), http.StatusMovedPermanently)
+	return true
+}
+
 func (s *Server) handleNotFound(w http.ResponseWriter, r *http.Request) {
+	// 所有未命中的路径最后都会落到这里，先看是不是 alias 声明过的旧地址
+	s.mu.RLock()
+	slug, ok := s.aliases[site.CleanPath(r.URL.Path)]
+	s.mu.RUnlock()
+	if ok && s.redirectToPost(w, r, slug) {
+		return
+	}
+
 	page := render.NotFoundPage{
-		Site: s.cfg.Site,
-		Path: r.URL.Path,
+		Site:  s.cfg.Site,
+		Path:  r.URL.Path,
+		Title: "404",
 	}
 	htmlBytes, err := s.tpl.RenderNotFound(r.Context(), page)
 	if err != nil {
@@ -546,10 +807,10 @@ func (s *Server) handleArchives(w http.ResponseWriter, r *http.Request) {
 	}
 
 	metas, err := s.idx.List(index.ListOptions{
-		Sort:         config.SortCreated,
-		Page:         1,
-		Size:         1000000,
-		IncludeDraft: true,
+		Sort:          config.SortCreated,
+		All:           true,
+		IncludeDraft:  true,
+		IncludeFuture: true,
 	})
 	if err != nil {
 		log.Printf("archives query error: %v", err)
@@ -611,10 +872,10 @@ func (s *Server) handleTagsRoot(w http.ResponseWriter, r *http.Request) {
 	}
 
 	metas, err := s.idx.List(index.ListOptions{
-		Sort:         s.cfg.Site.SortMode,
-		Page:         1,
-		Size:         1000000,
-		IncludeDraft: true,
+		Sort:          s.cfg.Site.SortMode,
+		All:           true,
+		IncludeDraft:  true,
+		IncludeFuture: true,
 	})
 	if err != nil {
 		log.Printf("tags query error: %v", err)
@@ -669,10 +930,10 @@ func (s *Server) handleCategoriesRoot(w http.ResponseWriter, r *http.Request) {
 	}
 
 	metas, err := s.idx.List(index.ListOptions{
-		Sort:         s.cfg.Site.SortMode,
-		Page:         1,
-		Size:         1000000,
-		IncludeDraft: true,
+		Sort:          s.cfg.Site.SortMode,
+		All:           true,
+		IncludeDraft:  true,
+		IncludeFuture: true,
 	})
 	if err != nil {
 		log.Printf("categories query error: %v", err)
@@ -733,35 +994,13 @@ func (s *Server) handleStaticSlug(slug string) http.HandlerFunc {
 			return
 		}
 
-		meta := art.Meta
-		src, err := os.ReadFile(art.Body.SourcePath)
+		pp, err := s.postPage(art)
 		if err != nil {
-			log.Printf("read source error: %v", err)
-			http.Error(w, "read source error", http.StatusInternalServerError)
-			return
-		}
-		_, body, fmErr := ingest.ParseFrontMatter(src)
-		if fmErr != nil {
-			body = src
-		}
-
-		mdResult, err := s.md.Render(body)
-		if err != nil {
-			log.Printf("markdown render error: %v", err)
-			http.Error(w, "markdown render error", http.StatusInternalServerError)
+			log.Printf("[serve] %v", err)
+			http.Error(w, "render page error", http.StatusInternalServerError)
 			return
 		}
 
-		pp := render.PostPage{
-			Site:       s.cfg.Site,
-			Meta:       meta,
-			HTML:       template.HTML(mdResult.HTML),
-			TOC:        mdResult.Headings,
-			IsDraft:    meta.Draft,
-			SeriesName: meta.Series.Name,
-			Title:      meta.Title,
-		}
-
 		htmlBytes, err := s.tpl.RenderPost(r.Context(), pp)
 		if err != nil {
 			log.Printf("render page error: %v", err)
@@ -774,6 +1013,24 @@ func (s *Server) handleStaticSlug(slug string) http.HandlerFunc {
 
 // ===================== 工具 =====================
 
+// splitPage 拆出路径末尾的 "page/N"：
+// "go/page/2" -> ("go", 2)；"go" -> ("go", 1)；页码非法时 ok 为 false
+func splitPage(p string) (string, int, bool) {
+	rest, num := p, ""
+	if i := strings.LastIndex(p, "/page/"); i >= 0 {
+		rest, num = p[:i], p[i+len("/page/"):]
+	} else if strings.HasPrefix(p, "page/") {
+		rest, num = "", p[len("page/"):]
+	} else {
+		return p, 1, true
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 1 {
+		return "", 0, false
+	}
+	return rest, n, true
+}
+
 func writeHTML(w http.ResponseWriter, data []byte) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	_, _ = w.Write(data)
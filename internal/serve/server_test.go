@@ -0,0 +1,42 @@
+package serve
+
+import (
+	"mygo/internal/domain/content"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServeBundleAsset(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{"hello/img/x.png": "png", "hello/index.md": "md", "sibling.md": "md"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	art := content.Article{Body: content.BodyRef{
+		SourcePath: filepath.Join(dir, "hello", "index.md"),
+		BundleDir:  filepath.Join(dir, "hello"),
+		Assets:     []string{"img/x.png"},
+	}}
+	s := &Server{articles: map[string]content.Article{"hello": art}}
+
+	rec := httptest.NewRecorder()
+	s.handlePost(rec, httptest.NewRequest(http.MethodGet, "/post/2024/01/02/hello/img/x.png", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "png" {
+		t.Errorf("asset: %d %q, want 200 png", rec.Code, rec.Body.String())
+	}
+
+	// 只输出登记过的资源：index.md 本身、包外的文件都不行
+	for _, rel := range []string{"index.md", "../sibling.md", "img/missing.png"} {
+		if s.serveBundleAsset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), art, rel) {
+			t.Errorf("served unregistered file %s", rel)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package sitemap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"mygo/internal/domain/config"
+	"mygo/internal/domain/site"
+	"strings"
+	"time"
+)
+
+const (
+	FileName   = "sitemap.xml"
+	RobotsFile = "robots.txt"
+
+	// MaxURLs 是协议规定的单个 sitemap 文件最多能放的 URL 数
+	MaxURLs = 50000
+)
+
+const xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type URL struct {
+	Path    string // 站内路径，如 /post/2024/01/02/slug/
+	LastMod time.Time
+}
+
+// File 是一个需要写出的 sitemap 文件
+type File struct {
+	Name string
+	Data []byte
+}
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type urlEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name   `xml:"sitemapindex"`
+	Xmlns    string     `xml:"xmlns,attr"`
+	Sitemaps []urlEntry `xml:"sitemap"`
+}
+
+// Build 生成 sitemap 文件。URL 数不超过 MaxURLs 时只有一个 sitemap.xml；
+// 超过时拆成 sitemap-1.xml、sitemap-2.xml ...，sitemap.xml 变成 sitemap index。
+func Build(siteURL string, urls []URL) ([]File, error) {
+	if len(urls) <= MaxURLs {
+		data, err := marshalURLSet(siteURL, urls)
+		if err != nil {
+			return nil, err
+		}
+		return []File{{Name: FileName, Data: data}}, nil
+	}
+
+	var files []File
+	idx := sitemapIndex{Xmlns: xmlns}
+	for i := 0; i*MaxURLs < len(urls); i++ {
+		end := (i + 1) * MaxURLs
+		if end > len(urls) {
+			end = len(urls)
+		}
+		chunk := urls[i*MaxURLs : end]
+		data, err := marshalURLSet(siteURL, chunk)
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("sitemap-%d.xml", i+1)
+		files = append(files, File{Name: name, Data: data})
+		idx.Sitemaps = append(idx.Sitemaps, urlEntry{
+			Loc:     site.AbsURL(siteURL, name),
+			LastMod: formatLastMod(latest(chunk)),
+		})
+	}
+	data, err := marshalXML(idx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]File{{Name: FileName, Data: data}}, files...), nil
+}
+
+// Robots 生成 robots.txt，默认全站允许，并指向 sitemap
+func Robots(siteURL string, rc config.RobotsConfig) []byte {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if len(rc.Disallow) == 0 {
+		b.WriteString("Allow: /\n")
+	}
+	for _, d := range rc.Disallow {
+		if d = strings.TrimSpace(d); d != "" {
+			b.WriteString("Disallow: " + d + "\n")
+		}
+	}
+	b.WriteString("\nSitemap: " + site.AbsURL(siteURL, FileName) + "\n")
+	if extra := strings.TrimSpace(rc.Extra); extra != "" {
+		b.WriteString("\n" + extra + "\n")
+	}
+	return []byte(b.String())
+}
+
+func marshalURLSet(siteURL string, urls []URL) ([]byte, error) {
+	set := urlSet{Xmlns: xmlns, URLs: make([]urlEntry, 0, len(urls))}
+	for _, u := range urls {
+		set.URLs = append(set.URLs, urlEntry{
+			Loc:     site.AbsURL(siteURL, u.Path),
+			LastMod: formatLastMod(u.LastMod),
+		})
+	}
+	return marshalXML(set)
+}
+
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func latest(urls []URL) time.Time {
+	var t time.Time
+	for _, u := range urls {
+		if u.LastMod.After(t) {
+			t = u.LastMod
+		}
+	}
+	return t
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,73 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func testURLs(n int) []URL {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	urls := make([]URL, n)
+	for i := range urls {
+		urls[i] = URL{Path: fmt.Sprintf("/p/%d/", i), LastMod: base.Add(time.Duration(i%100) * time.Hour)}
+	}
+	return urls
+}
+
+func TestBuildSingleFile(t *testing.T) {
+	files, err := Build("https://example.com/", testURLs(MaxURLs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name != FileName {
+		t.Fatalf("files = %d, want only %s", len(files), FileName)
+	}
+	var set urlSet
+	if err := xml.Unmarshal(files[0].Data, &set); err != nil {
+		t.Fatal(err)
+	}
+	if len(set.URLs) != MaxURLs || set.URLs[1].Loc != "https://example.com/p/1/" {
+		t.Errorf("urlset has %d URLs, second %+v", len(set.URLs), set.URLs[1])
+	}
+}
+
+// 超过 MaxURLs 时拆分，sitemap.xml 变成指向各个分片的 sitemap index
+func TestBuildSplitsIntoIndex(t *testing.T) {
+	urls := testURLs(MaxURLs + 1)
+	urls[MaxURLs].LastMod = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	files, err := Build("https://example.com", urls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	if fmt.Sprint(names) != "[sitemap.xml sitemap-1.xml sitemap-2.xml]" {
+		t.Fatalf("files = %v", names)
+	}
+
+	var idx sitemapIndex
+	if err := xml.Unmarshal(files[0].Data, &idx); err != nil {
+		t.Fatal(err)
+	}
+	want := []urlEntry{
+		{Loc: "https://example.com/sitemap-1.xml", LastMod: "2024-01-05T03:00:00Z"},
+		{Loc: "https://example.com/sitemap-2.xml", LastMod: "2025-06-01T00:00:00Z"},
+	}
+	if fmt.Sprint(idx.Sitemaps) != fmt.Sprint(want) {
+		t.Errorf("index = %v, want %v", idx.Sitemaps, want)
+	}
+
+	for i, n := range []int{MaxURLs, 1} {
+		var set urlSet
+		if err := xml.Unmarshal(files[i+1].Data, &set); err != nil {
+			t.Fatal(err)
+		}
+		if len(set.URLs) != n {
+			t.Errorf("%s has %d URLs, want %d", files[i+1].Name, len(set.URLs), n)
+		}
+	}
+}
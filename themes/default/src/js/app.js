@@ -82,7 +82,7 @@
 
             searchResults.innerHTML = showHits.map(hit => `
         <div class="search-hit">
-          <a href="/post/${hit.slug}" target="_self">
+          <a href="${hit.url || "/post/" + hit.slug}" target="_self">
             <strong>${hit.title || hit.slug}</strong>
             <small>${hit.date} ${hit.tags ? "· " + hit.tags.join(" ") : ""}</small>
           </a>
@@ -111,6 +111,8 @@
                 if (item.title && item.title.toLowerCase().includes(lowerQ)) return true;
                 if (item.slug && item.slug.toLowerCase().includes(lowerQ)) return true;
                 if (item.tags && item.tags.some(t => t.toLowerCase().includes(lowerQ))) return true;
+                if (item.summary && item.summary.toLowerCase().includes(lowerQ)) return true;
+                if (item.content && item.content.toLowerCase().includes(lowerQ)) return true;
                 return false;
             });
             renderResults(hits);
@@ -361,16 +363,9 @@
                 p = Math.min(Math.max(p, 1), total);
             }
 
+            // 第一页是 base 本身，其余为 base + "page/N/"，与 build 输出的目录一致
             const base = form.dataset.base || "/";
-            const mode = (form.dataset.mode || "section").toLowerCase();
-
-            let href;
-            if (mode === "home") {
-                href = p <= 1 ? "/" : `/page/${p}`;
-            } else {
-                href = p <= 1 ? base : `${base}/${p}`;
-            }
-            location.href = href;
+            location.href = p <= 1 ? base : `${base.replace(/\/+$/, "")}/page/${p}/`;
         }, { passive: false });
 
         document.addEventListener("keydown", (e) => {
@@ -381,6 +376,22 @@
         }, { passive: true });
     }
 
+    function initCopyLinks() {
+        // 带 data-copy 的链接（如文章短链）点击时复制完整地址而不是跳转
+        document.addEventListener("click", (e) => {
+            const link = e.target.closest("a[data-copy]");
+            if (!link || !navigator.clipboard) return;
+            e.preventDefault();
+            navigator.clipboard.writeText(link.dataset.copy).then(() => {
+                const text = link.textContent;
+                link.textContent = "已复制";
+                setTimeout(() => (link.textContent = text), 1500);
+            }).catch(() => {
+                location.href = link.href;
+            });
+        });
+    }
+
     onReady(() => {
         initSearch();
         initCodeBlocks();
@@ -388,5 +399,6 @@
         initTOC();
         initImages();
         initPagination();
+        initCopyLinks();
     });
 })();
\ No newline at end of file